@@ -0,0 +1,172 @@
+// Package appconfig provides a source for values in an AWS AppConfig profile.
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/andreaperizzato/go-config"
+	"gopkg.in/yaml.v3"
+)
+
+// Tag is the name of the tag to load values from an AppConfig profile.
+// The key is the dotted path of the value in the configuration: appconfig:"new_checkout.enabled".
+const Tag = "appconfig"
+
+// DefaultPollInterval is the time between polls when AppConfig doesn't return one.
+const DefaultPollInterval = time.Minute
+
+// Client is the subset of the AppConfig data plane API used by the source.
+// It can be implemented on top of the appconfigdata package of the AWS SDK.
+type Client interface {
+	// StartConfigurationSession starts a session for the profile of the application in
+	// the environment, and returns the token for the first GetLatestConfiguration call.
+	StartConfigurationSession(application, environment, profile string) (string, error)
+	// GetLatestConfiguration returns the latest configuration for the token.
+	GetLatestConfiguration(token string) (*Configuration, error)
+}
+
+// Configuration is the response of GetLatestConfiguration.
+type Configuration struct {
+	// Configuration is empty if it didn't change since the last call.
+	Configuration []byte
+	// ContentType is the content type of the configuration, e.g. application/json.
+	ContentType string
+	// NextPollConfigurationToken is the token to use for the next call.
+	NextPollConfigurationToken string
+	// NextPollInterval is the minimum time before the next call.
+	NextPollInterval time.Duration
+}
+
+// Config is the configuration for the creation of an AppConfig Source.
+type Config struct {
+	Client      Client
+	Application string
+	Environment string
+	Profile     string
+}
+
+// NewSource creates a Source for values in an AppConfig profile,
+// which can be a feature flags or a freeform JSON or YAML profile.
+// The configuration is only requested again after the poll interval returned by AppConfig.
+func NewSource(client Client, application, environment, profile string) config.Source {
+	return NewSourceWithConfig(Config{
+		Client:      client,
+		Application: application,
+		Environment: environment,
+		Profile:     profile,
+	})
+}
+
+// NewSourceWithConfig creates a Source for values in an AppConfig profile specifying custom configuration.
+func NewSourceWithConfig(cfg Config) config.Source {
+	return &source{cfg: cfg, now: time.Now}
+}
+
+type source struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	token    string
+	doc      config.LookupSource
+	nextPoll time.Time
+}
+
+var _sourceIfaceCheck config.LookupSource = &source{}
+var _sourceSnapshotIfaceCheck config.SnapshotSource = &source{}
+
+func (s *source) Tag() string {
+	return Tag
+}
+
+func (s *source) Get(tag config.TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *source) Lookup(tag config.TagValue) (string, bool, error) {
+	doc, err := s.document()
+	if err != nil {
+		return "", false, err
+	}
+	return doc.Lookup(tag)
+}
+
+// Snapshot returns a Source for the values of the configuration, so that all
+// the fields of a load are read from the same version.
+func (s *source) Snapshot() (config.Source, error) {
+	return s.document()
+}
+
+// document polls the configuration if the poll interval elapsed, and returns its values.
+func (s *source) document() (config.LookupSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.now().Before(s.nextPoll) {
+		if err := s.poll(); err != nil {
+			return nil, err
+		}
+	}
+	if s.doc == nil {
+		// Wait for the next poll, even if AppConfig didn't return a configuration yet.
+		return nil, fmt.Errorf("appconfig: empty configuration for %s", s.cfg.Profile)
+	}
+	return s.doc, nil
+}
+
+// poll gets the latest configuration, starting a session if needed.
+func (s *source) poll() error {
+	if s.cfg.Client == nil {
+		return errors.New("appconfig: client is not set")
+	}
+	if s.token == "" {
+		token, err := s.cfg.Client.StartConfigurationSession(s.cfg.Application, s.cfg.Environment, s.cfg.Profile)
+		if err != nil {
+			return err
+		}
+		s.token = token
+	}
+	out, err := s.cfg.Client.GetLatestConfiguration(s.token)
+	if err != nil {
+		// The token can't be used again after an error, e.g. when it expired.
+		s.token = ""
+		return err
+	}
+	s.token = out.NextPollConfigurationToken
+	interval := out.NextPollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	s.nextPoll = s.now().Add(interval)
+	if len(out.Configuration) == 0 {
+		return nil
+	}
+	doc, err := decode(out.Configuration, out.ContentType)
+	if err != nil {
+		return fmt.Errorf("appconfig: invalid configuration for %s: %v", s.cfg.Profile, err)
+	}
+	s.doc = doc
+	return nil
+}
+
+// decode decodes a JSON or YAML configuration, based on its content type.
+func decode(b []byte, contentType string) (config.LookupSource, error) {
+	if strings.Contains(contentType, "yaml") {
+		var v interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		// Convert the document to JSON, so that values are formatted like in JSON configurations.
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	return config.NewDocumentSource(Tag, bytes.NewReader(b))
+}
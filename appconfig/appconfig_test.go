@@ -0,0 +1,231 @@
+package appconfig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+type testAppConfig struct {
+	sessions int
+	polls    []string
+	configs  []*Configuration
+	err      error
+}
+
+func (c *testAppConfig) StartConfigurationSession(application, environment, profile string) (string, error) {
+	if application != "app" || environment != "prod" || profile != "flags" {
+		return "", fmt.Errorf("unknown profile %s/%s/%s", application, environment, profile)
+	}
+	c.sessions++
+	return fmt.Sprintf("session-%d", c.sessions), nil
+}
+
+func (c *testAppConfig) GetLatestConfiguration(token string) (*Configuration, error) {
+	c.polls = append(c.polls, token)
+	if c.err != nil {
+		return nil, c.err
+	}
+	out := &Configuration{
+		NextPollConfigurationToken: fmt.Sprintf("%s-poll-%d", token, len(c.polls)),
+		NextPollInterval:           30 * time.Second,
+	}
+	if len(c.configs) > 0 {
+		next := c.configs[0]
+		c.configs = c.configs[1:]
+		out.Configuration = next.Configuration
+		out.ContentType = next.ContentType
+	}
+	return out, nil
+}
+
+func TestSource(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config Configuration
+	}{
+		{
+			desc: "json",
+			config: Configuration{
+				ContentType:   "application/json",
+				Configuration: []byte(`{"new_checkout":{"enabled":true,"ratio":0.25},"regions":["eu","us"]}`),
+			},
+		},
+		{
+			desc: "yaml",
+			config: Configuration{
+				ContentType:   "application/x-yaml",
+				Configuration: []byte("new_checkout:\n  enabled: true\n  ratio: 0.25\nregions:\n  - eu\n  - us\n"),
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cfg := tC.config
+			client := &testAppConfig{configs: []*Configuration{&cfg}}
+			s := NewSource(client, "app", "prod", "flags")
+			if s.Tag() != "appconfig" {
+				t.Fatalf("expected tag to be '%s' but was '%s'", "appconfig", s.Tag())
+			}
+			var settings struct {
+				Enabled bool   `appconfig:"new_checkout.enabled"`
+				Ratio   string `appconfig:"new_checkout.ratio"`
+				Region  string `appconfig:"regions.1"`
+				Missing string `appconfig:"old_checkout.enabled" default:"off"`
+			}
+			if err := config.NewLoader(s).Load(&settings); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !settings.Enabled || settings.Ratio != "0.25" || settings.Region != "us" || settings.Missing != "off" {
+				t.Fatalf("unexpected values: %+v", settings)
+			}
+			if client.sessions != 1 || len(client.polls) != 1 {
+				t.Fatalf("expected 1 session and 1 poll but were %d and %d", client.sessions, len(client.polls))
+			}
+		})
+	}
+}
+
+func TestSourcePolling(t *testing.T) {
+	client := &testAppConfig{configs: []*Configuration{
+		{ContentType: "application/json", Configuration: []byte(`{"limit":1}`)},
+		// Unchanged.
+		{},
+		{ContentType: "application/json", Configuration: []byte(`{"limit":2}`)},
+	}}
+	now := time.Now()
+	s := NewSource(client, "app", "prod", "flags").(*source)
+	s.now = func() time.Time { return now }
+
+	get := func(expected string) {
+		t.Helper()
+		out, err := s.Get(config.TagValue{Name: "limit"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != expected {
+			t.Fatalf("expected '%s' but was '%s'", expected, out)
+		}
+	}
+	get("1")
+	// The poll interval didn't elapse.
+	now = now.Add(10 * time.Second)
+	get("1")
+	now = now.Add(20 * time.Second)
+	get("1")
+	now = now.Add(30 * time.Second)
+	get("2")
+
+	expected := []string{"session-1", "session-1-poll-1", "session-1-poll-1-poll-2"}
+	if fmt.Sprint(client.polls) != fmt.Sprint(expected) {
+		t.Fatalf("expected polls with tokens %v but were %v", expected, client.polls)
+	}
+
+	// A new session is started after an error.
+	client.err = errors.New("token expired")
+	now = now.Add(30 * time.Second)
+	if _, err := s.Get(config.TagValue{Name: "limit"}); err == nil || err.Error() != "token expired" {
+		t.Fatalf("expected error to be 'token expired' but was '%v'", err)
+	}
+	client.err = nil
+	get("2")
+	if client.sessions != 2 {
+		t.Fatalf("expected 2 sessions but was %d", client.sessions)
+	}
+}
+
+func TestSourceSnapshot(t *testing.T) {
+	client := &testAppConfig{configs: []*Configuration{
+		{ContentType: "application/json", Configuration: []byte(`{"a":"1","b":"1"}`)},
+		{ContentType: "application/json", Configuration: []byte(`{"a":"2","b":"2"}`)},
+	}}
+	// The poll interval elapses while loading.
+	now := time.Now()
+	s := NewSource(client, "app", "prod", "flags").(*source)
+	s.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	var settings struct {
+		A string `appconfig:"a"`
+		B string `appconfig:"b"`
+	}
+	if err := config.NewLoader(s).Load(&settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.A != "1" || settings.B != "1" {
+		t.Fatalf("expected the values of a single version but were %+v", settings)
+	}
+	if len(client.polls) != 1 {
+		t.Fatalf("expected 1 poll but was %d", len(client.polls))
+	}
+}
+
+func TestSourceEmptyConfiguration(t *testing.T) {
+	client := &testAppConfig{configs: []*Configuration{
+		{},
+		{ContentType: "application/json", Configuration: []byte(`{"limit":1}`)},
+	}}
+	now := time.Now()
+	s := NewSource(client, "app", "prod", "flags").(*source)
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := s.Get(config.TagValue{Name: "limit"})
+		if err == nil || err.Error() != "appconfig: empty configuration for flags" {
+			t.Fatalf("expected error to be 'appconfig: empty configuration for flags' but was '%v'", err)
+		}
+	}
+	// The poll interval is respected even without a configuration.
+	if len(client.polls) != 1 {
+		t.Fatalf("expected 1 poll but was %d", len(client.polls))
+	}
+	now = now.Add(30 * time.Second)
+	out, err := s.Get(config.TagValue{Name: "limit"})
+	if err != nil || out != "1" {
+		t.Fatalf("expected '1' but was '%s' (%v)", out, err)
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		client Client
+		err    string
+	}{
+		{
+			desc: "no client",
+			err:  "appconfig: client is not set",
+		},
+		{
+			desc:   "empty configuration",
+			client: &testAppConfig{},
+			err:    "appconfig: empty configuration for flags",
+		},
+		{
+			desc: "invalid configuration",
+			client: &testAppConfig{configs: []*Configuration{
+				{ContentType: "application/json", Configuration: []byte(`{`)},
+			}},
+			err: "appconfig: invalid configuration for flags: unexpected EOF",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			s := NewSourceWithConfig(Config{
+				Client:      tC.client,
+				Application: "app",
+				Environment: "prod",
+				Profile:     "flags",
+			})
+			_, err := s.Get(config.TagValue{Name: "limit"})
+			if err == nil || err.Error() != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%v'", tC.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// CheckStatus is the status of a field in a CheckReport.
+type CheckStatus string
+
+const (
+	// CheckOK is the status of a field whose value was provided by a source.
+	CheckOK CheckStatus = "ok"
+	// CheckDefaulted is the status of a field that uses the default value.
+	CheckDefaulted CheckStatus = "defaulted"
+	// CheckUnset is the status of a field with the deprecated optional flag
+	// that no source provides, which keeps its zero value.
+	CheckUnset CheckStatus = "unset"
+	// CheckMissing is the status of a required field that no source provides,
+	// or that no source allowed by its from tag provides.
+	CheckMissing CheckStatus = "missing"
+	// CheckInvalid is the status of a field whose value can't be parsed or is not valid.
+	CheckInvalid CheckStatus = "invalid"
+	// CheckError is the status of a field that couldn't be loaded because of an error in a source.
+	CheckError CheckStatus = "error"
+)
+
+// passed returns true if the status doesn't make loading fail.
+func (s CheckStatus) passed() bool {
+	return s == CheckOK || s == CheckDefaulted || s == CheckUnset
+}
+
+// FieldCheck is the result of checking a field.
+type FieldCheck struct {
+	// Path is the path of the field, e.g. DB.Host. For the checks of
+	// Validator, it's the path of the struct and empty for the root.
+	Path   string          `json:"path"`
+	Status CheckStatus     `json:"status"`
+	Source FieldProvenance `json:"source"`
+	// Error describes why the field is not OK.
+	Error string `json:"error,omitempty"`
+}
+
+// CheckReport is the result of Loader.Check.
+type CheckReport struct {
+	Fields []FieldCheck `json:"fields"`
+}
+
+// OK returns true if loading would succeed.
+func (r *CheckReport) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the checks of the fields that would make loading fail.
+func (r *CheckReport) Failed() []FieldCheck {
+	var failed []FieldCheck
+	for _, f := range r.Fields {
+		if !f.Status.passed() {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// Write writes the report to w, one field per line.
+func (r *CheckReport) Write(w io.Writer) error {
+	for _, f := range r.Fields {
+		line := fmt.Sprintf("%-9s %s", f.Status, f.Path)
+		switch {
+		case f.Error != "":
+			line += ": " + f.Error
+		case f.Status == CheckOK || len(f.Source.Overridden) > 0:
+			line += " (" + describeSource(f.Source) + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check resolves the value of every field of v, which must be a struct or a pointer to a
+// struct, without setting them. Unlike Load, it doesn't stop at the first error and reports
+// the status of every field instead, e.g. to verify that all the required values are
+// available before deploying.
+func (c *Loader) Check(v interface{}) (*CheckReport, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("config: v is not a struct")
+	}
+	// Work on a copy, so that v is never modified.
+	cp := reflect.New(rv.Type()).Elem()
+	cp.Set(rv)
+	r := &CheckReport{}
+	c.snapshot().checkStruct(cp, "", r)
+	return r, nil
+}
+
+// checkStruct checks the fields of rv and returns true if they are all OK.
+func (c *Loader) checkStruct(rv reflect.Value, path string, r *CheckReport) bool {
+	ok := true
+	rt := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		f := field{StructField: rt.Field(i), path: rt.Field(i).Name}
+		if path != "" {
+			f.path = path + "." + f.path
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct && fv.CanSet() && !c.hasSourceTag(f) {
+			ok = c.checkStruct(fv, f.path, r) && ok
+			continue
+		}
+		check, matched := c.checkField(fv, f)
+		if !matched {
+			continue
+		}
+		r.Fields = append(r.Fields, check)
+		ok = ok && check.Status.passed()
+	}
+	// Cross-field validation is only meaningful when all the fields are valid.
+	if !ok {
+		return false
+	}
+	if err := validateStruct(rv, path); err != nil {
+		r.Fields = append(r.Fields, FieldCheck{Path: path, Status: CheckInvalid, Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+// checkField checks the field, returning false if it's not loaded from any source.
+func (c *Loader) checkField(fv reflect.Value, f field) (FieldCheck, bool) {
+	val, err := c.loadFieldValue(f)
+	if !val.matched {
+		return FieldCheck{}, false
+	}
+	check := FieldCheck{
+		Path:   f.path,
+		Status: CheckOK,
+		Source: val.provenance,
+	}
+	if !val.explicit {
+		check.Status = CheckDefaulted
+		if !val.provenance.Default {
+			check.Status = CheckUnset
+		}
+	}
+	if err != nil {
+		check.Status = CheckError
+		switch err.(type) {
+		case *missingValue, *disallowedSource:
+			check.Status = CheckMissing
+		}
+		check.Error = err.Error()
+		return check, true
+	}
+
+	err = setFieldValue(fv, f, val)
+	if err != nil {
+		check.Status = CheckInvalid
+		check.Error = err.Error()
+	}
+	return check, true
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type testCheckSettings struct {
+	Host    string `test:"host" happy:"host"`
+	Port    int    `test:"port" default:"80"`
+	Workers int    `test:"workers"`
+	Level   string `test:"level" oneof:"debug info"`
+	Key     string `test:"key"`
+	Broken  string `happy:"broken"`
+	Other   string
+	Pool    testPool
+}
+
+func TestCheck(t *testing.T) {
+	s1 := &testSource{tag: "test", values: map[string]string{
+		"host":    "localhost",
+		"port":    "",
+		"workers": "many",
+		"level":   "trace",
+		"key":     "",
+		"min":     "3",
+		"max":     "2",
+	}}
+	s2 := &testSource{tag: "happy", values: map[string]string{
+		"host": "example.com",
+	}}
+	v := testCheckSettings{Other: "other"}
+	orig := v
+
+	r, err := NewLoader(s1, s2).Check(&v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []FieldCheck{
+		{Path: "Host", Status: CheckOK, Source: FieldProvenance{Tag: "happy", Key: "host", Overridden: []string{"test"}}},
+		{Path: "Port", Status: CheckDefaulted, Source: FieldProvenance{Default: true}},
+		{Path: "Workers", Status: CheckInvalid, Source: FieldProvenance{Tag: "test", Key: "workers"}, Error: `strconv.ParseInt: parsing "many": invalid syntax`},
+		{Path: "Level", Status: CheckInvalid, Source: FieldProvenance{Tag: "test", Key: "level"}, Error: "config: invalid value for field 'Level': must be one of debug, info"},
+		{Path: "Key", Status: CheckMissing, Error: "config: missing value for field 'Key'"},
+		{Path: "Broken", Status: CheckError, Error: "config: error loading field Broken for tag happy: error getting key broken"},
+		{Path: "Pool.Min", Status: CheckOK, Source: FieldProvenance{Tag: "test", Key: "min"}},
+		{Path: "Pool.Max", Status: CheckOK, Source: FieldProvenance{Tag: "test", Key: "max"}},
+		{Path: "Pool", Status: CheckInvalid, Error: "config: invalid configuration for 'Pool': min 3 is greater than max 2"},
+	}
+	if !reflect.DeepEqual(out, r.Fields) {
+		t.Errorf("expected fields to be %+v but was %+v", out, r.Fields)
+	}
+	if r.OK() {
+		t.Errorf("expected report not to be OK")
+	}
+	if len(r.Failed()) != 5 {
+		t.Errorf("expected %d failed fields but got %d", 5, len(r.Failed()))
+	}
+	if !reflect.DeepEqual(orig, v) {
+		t.Errorf("expected v not to be modified but was %v", v)
+	}
+
+	var buf bytes.Buffer
+	err = r.Write(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := `ok        Host (happy: host, overrides test)
+defaulted Port
+invalid   Workers: strconv.ParseInt: parsing "many": invalid syntax
+invalid   Level: config: invalid value for field 'Level': must be one of debug, info
+missing   Key: config: missing value for field 'Key'
+error     Broken: config: error loading field Broken for tag happy: error getting key broken
+ok        Pool.Min (test: min)
+ok        Pool.Max (test: max)
+invalid   Pool: config: invalid configuration for 'Pool': min 3 is greater than max 2
+`
+	if buf.String() != text {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", text, buf.String())
+	}
+}
+
+func TestCheckOK(t *testing.T) {
+	s := &testSource{values: map[string]string{"min": "1", "max": "2"}}
+	r, err := NewLoader(s).Check(testPool{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.OK() {
+		t.Errorf("expected report to be OK but failed with %v", r.Failed())
+	}
+}
+
+func TestCheckDeprecatedOptional(t *testing.T) {
+	s := &testSource{tag: "test", values: map[string]string{"key": ""}}
+	var v struct {
+		Key string `test:"key,optional"`
+	}
+	r, err := NewLoader(s).Check(&v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []FieldCheck{{Path: "Key", Status: CheckUnset}}
+	if !reflect.DeepEqual(out, r.Fields) {
+		t.Errorf("expected fields to be %+v but was %+v", out, r.Fields)
+	}
+	if !r.OK() {
+		t.Errorf("expected report to be OK but failed with %v", r.Failed())
+	}
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "unset     Key\n" {
+		t.Errorf("expected output to be 'unset     Key' but was '%s'", buf.String())
+	}
+}
+
+func TestCheckFromTag(t *testing.T) {
+	s := &testSource{tag: "test", values: map[string]string{"key": "value"}}
+	v := struct {
+		Key string `test:"key" from:"ssm"`
+	}{}
+
+	r, err := NewLoader(s).Check(&v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []FieldCheck{
+		{Path: "Key", Status: CheckMissing, Error: "config: field 'Key' must be loaded from ssm but was only set by test"},
+	}
+	if !reflect.DeepEqual(out, r.Fields) {
+		t.Errorf("expected fields to be %+v but was %+v", out, r.Fields)
+	}
+}
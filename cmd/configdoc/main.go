@@ -0,0 +1,155 @@
+// Command configdoc documents the configuration keys of a settings struct.
+//
+// It reads the struct from the Go source files in a directory and describes
+// every field loaded by config.Loader, with the same tag rules:
+//
+//	configdoc -type Settings -sources ssm,env -env-prefix MYAPP_ > CONFIG.md
+//
+// It can also generate templates to bootstrap a new environment:
+//
+//	configdoc -type Settings -format env > .env.example
+//	configdoc -type Settings -format ssm-yaml > parameters.yaml
+//	configdoc -type Settings -format kubernetes -name myapp > config.yaml
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("configdoc: ")
+
+	dir := flag.String("dir", ".", "directory containing the Go source files")
+	typeName := flag.String("type", "", "name of the settings struct (required)")
+	format := flag.String("format", "markdown", "output format: markdown, json, env, ssm-json, ssm-yaml or kubernetes")
+	name := flag.String("name", "config", "name of the Kubernetes ConfigMap and Secret")
+	sources := flag.String("sources", "env,ssm", "comma separated tags of the sources, in the order they are loaded")
+	envPrefix := flag.String("env-prefix", "", "prefix of the environment variables")
+	ssmSubs := flag.String("ssm-subs", "", "comma separated SSM substitutions, e.g. stage=prod")
+	naming := flag.String("naming", "", "comma separated naming functions by tag, e.g. env=upper-snake,ssm=lower-kebab")
+	auto := flag.Bool("auto", false, "load fields that are not tagged for a source")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	v, err := parseStruct(*dir, *typeName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := loaderConfig(*sources, *envPrefix, *ssmSubs, *naming)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.Auto = *auto
+
+	specs, err := config.NewLoaderWithConfig(cfg).Spec(v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = write(os.Stdout, *format, *name, specs)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(w io.Writer, format, name string, specs []config.FieldSpec) error {
+	switch format {
+	case "markdown":
+		return config.WriteSpecMarkdown(w, specs)
+	case "json":
+		return config.WriteSpecJSON(w, specs)
+	case "env":
+		return config.WriteEnvExample(w, specs)
+	case "ssm-json":
+		return config.WriteSSMParametersJSON(w, specs)
+	case "ssm-yaml":
+		return config.WriteSSMParametersYAML(w, specs)
+	case "kubernetes":
+		return config.WriteKubernetesManifests(w, specs, name)
+	}
+	return fmt.Errorf("unknown format %s", format)
+}
+
+var nameFuncs = map[string]config.NameFunc{
+	"upper-snake": config.UpperSnakeCase,
+	"lower-snake": config.LowerSnakeCase,
+	"lower-kebab": config.LowerKebabCase,
+}
+
+func loaderConfig(sources, envPrefix, ssmSubs, naming string) (cfg config.LoaderConfig, err error) {
+	subs, err := parsePairs(ssmSubs)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid -ssm-subs: %v", err)
+	}
+	for _, tag := range strings.Split(sources, ",") {
+		switch tag = strings.TrimSpace(tag); tag {
+		case "":
+			continue
+		case config.EnvTag:
+			cfg.Sources = append(cfg.Sources, config.NewEnvSourceWithConfig(config.EnvSourceConfig{
+				Prefix: envPrefix,
+			}))
+		case config.SSMTag:
+			cfg.Sources = append(cfg.Sources, config.NewSSMSourceWithConfig(config.SSMSourceConfig{
+				Substitutions: subs,
+			}))
+		default:
+			cfg.Sources = append(cfg.Sources, tagSource(tag))
+		}
+	}
+
+	names, err := parsePairs(naming)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid -naming: %v", err)
+	}
+	for tag, name := range names {
+		f, found := nameFuncs[name]
+		if !found {
+			return cfg, fmt.Errorf("invalid -naming: unknown naming function %s", name)
+		}
+		if cfg.Naming == nil {
+			cfg.Naming = make(map[string]config.NameFunc)
+		}
+		cfg.Naming[tag] = f
+	}
+	return cfg, nil
+}
+
+// parsePairs parses a comma separated list of key=value pairs.
+func parsePairs(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	pairs := make(map[string]string)
+	for _, kv := range strings.Split(s, ",") {
+		bits := strings.SplitN(kv, "=", 2)
+		if len(bits) != 2 {
+			return nil, fmt.Errorf("%s is not in the form key=value", kv)
+		}
+		pairs[strings.TrimSpace(bits[0])] = strings.TrimSpace(bits[1])
+	}
+	return pairs, nil
+}
+
+// tagSource is a Source that is only used for its tag, since values are never loaded.
+type tagSource string
+
+func (s tagSource) Tag() string {
+	return string(s)
+}
+
+func (s tagSource) Get(tag config.TagValue) (string, error) {
+	return "", errors.New("not supported")
+}
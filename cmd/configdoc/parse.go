@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// basicTypes are the types that can be loaded from a source.
+var basicTypes = map[string]reflect.Type{
+	"string":        reflect.TypeOf(""),
+	"bool":          reflect.TypeOf(false),
+	"int":           reflect.TypeOf(int(0)),
+	"int8":          reflect.TypeOf(int8(0)),
+	"int16":         reflect.TypeOf(int16(0)),
+	"int32":         reflect.TypeOf(int32(0)),
+	"int64":         reflect.TypeOf(int64(0)),
+	"uint":          reflect.TypeOf(uint(0)),
+	"uint8":         reflect.TypeOf(uint8(0)),
+	"uint16":        reflect.TypeOf(uint16(0)),
+	"uint32":        reflect.TypeOf(uint32(0)),
+	"uint64":        reflect.TypeOf(uint64(0)),
+	"time.Duration": reflect.TypeOf(time.Duration(0)),
+}
+
+// unsupportedType replaces the types that can't be loaded from a source,
+// so that the Loader reports them if they are tagged.
+var unsupportedType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// parseStruct finds the struct named typeName in the Go source files in dir,
+// and returns a pointer to a new value of an equivalent type built with reflect.
+func parseStruct(dir, typeName string) (interface{}, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		p := &structParser{types: make(map[string]ast.Expr), parsing: make(map[string]bool)}
+		for _, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					p.types[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+		expr, found := p.types[typeName]
+		if !found {
+			continue
+		}
+		if _, ok := expr.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("%s is not a struct", typeName)
+		}
+		t, err := p.typeOf(expr)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.New(t).Interface(), nil
+	}
+	return nil, fmt.Errorf("type %s not found in %s", typeName, dir)
+}
+
+type structParser struct {
+	types   map[string]ast.Expr
+	parsing map[string]bool
+}
+
+func (p *structParser) typeOf(expr ast.Expr) (reflect.Type, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if t, found := basicTypes[e.Name]; found {
+			return t, nil
+		}
+		declared, found := p.types[e.Name]
+		if !found || p.parsing[e.Name] {
+			return unsupportedType, nil
+		}
+		p.parsing[e.Name] = true
+		defer delete(p.parsing, e.Name)
+		return p.typeOf(declared)
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok {
+			if t, found := basicTypes[pkg.Name+"."+e.Sel.Name]; found {
+				return t, nil
+			}
+		}
+		return unsupportedType, nil
+	case *ast.StructType:
+		return p.structOf(e)
+	}
+	return unsupportedType, nil
+}
+
+func (p *structParser) structOf(st *ast.StructType) (reflect.Type, error) {
+	var fields []reflect.StructField
+	for _, f := range st.Fields.List {
+		t, err := p.typeOf(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{embeddedName(f.Type)}
+		}
+		for _, name := range names {
+			// Unexported fields can't be created with reflect.StructOf.
+			if name == nil || !ast.IsExported(name.Name) {
+				continue
+			}
+			fields = append(fields, reflect.StructField{
+				Name: name.Name,
+				Type: t,
+				Tag:  tag,
+			})
+		}
+	}
+	return reflect.StructOf(fields), nil
+}
+
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	}
+	return nil
+}
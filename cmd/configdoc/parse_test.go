@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testSource = `package sample
+
+import "time"
+
+type Level string
+
+type DB struct {
+	Host string ` + "`env:\"DB_HOST\"`" + `
+}
+
+type Settings struct {
+	Level   Level         ` + "`env:\"LOG_LEVEL\" default:\"info\"`" + `
+	Timeout time.Duration ` + "`env:\"TIMEOUT\"`" + `
+	DB
+	Other   []string
+	hidden  string
+}
+`
+
+func TestParseStruct(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configdoc")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "settings.go"), []byte(testSource), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error writing file: %v", err)
+	}
+
+	v, err := parseStruct(dir, "Settings")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rt := reflect.TypeOf(v).Elem()
+	out := []reflect.StructField{
+		{Name: "Level", Type: reflect.TypeOf(""), Tag: `env:"LOG_LEVEL" default:"info"`},
+		{Name: "Timeout", Type: reflect.TypeOf(time.Duration(0)), Tag: `env:"TIMEOUT"`},
+		{Name: "DB", Type: reflect.StructOf([]reflect.StructField{
+			{Name: "Host", Type: reflect.TypeOf(""), Tag: `env:"DB_HOST"`},
+		})},
+		{Name: "Other", Type: unsupportedType},
+	}
+	if rt.NumField() != len(out) {
+		t.Fatalf("expected %d fields but got %d", len(out), rt.NumField())
+	}
+	for i, f := range out {
+		ft := rt.Field(i)
+		if ft.Name != f.Name || ft.Type != f.Type || ft.Tag != f.Tag {
+			t.Errorf("expected field %d to be %v but was %v", i, f, ft)
+		}
+	}
+
+	_, err = parseStruct(dir, "Level")
+	if err == nil || err.Error() != "Level is not a struct" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = parseStruct(dir, "Missing")
+	if err == nil || err.Error() != "type Missing not found in "+dir {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+}
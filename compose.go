@@ -0,0 +1,180 @@
+package config
+
+import "sync"
+
+// FirstOf creates a Source for tag that reads the value from each of the sources in order,
+// and returns the first value that is not empty. The tags of the sources are ignored,
+// e.g. FirstOf("ssm", euSSM, usSSM) tries two SSM regions for the fields tagged with ssm.
+// The source is a WatchableSource if any of the sources is, and notifies their changes.
+func FirstOf(tag string, srcs ...Source) Source {
+	return newComposedSource(&composedSource{tag: tag, srcs: srcs})
+}
+
+// Layered creates a Source for tag that reads the value from layers of sources, where each
+// source overrides the ones before it: the value is taken from the last source that has it.
+// Empty values are only taken for fields with the allowempty flag, and only from sources that
+// implement LookupSource, which can tell an empty value from a missing one.
+// The tags of the sources are ignored, e.g. Layered("env", dotenv, NewEnvSource()) lets
+// the environment override a .env file for the fields tagged with env.
+// The source is a WatchableSource if any of the sources is, and notifies their changes.
+func Layered(tag string, srcs ...Source) Source {
+	return newComposedSource(&composedSource{tag: tag, srcs: srcs, layered: true})
+}
+
+// newComposedSource returns s, or a watchable version of s if any of its sources is watchable.
+func newComposedSource(s *composedSource) Source {
+	var watchable []WatchableSource
+	for _, src := range s.srcs {
+		if ws, ok := src.(WatchableSource); ok {
+			watchable = append(watchable, ws)
+		}
+	}
+	if len(watchable) == 0 {
+		return s
+	}
+	return &watchableComposedSource{composedSource: s, watchable: watchable}
+}
+
+type composedSource struct {
+	tag     string
+	srcs    []Source
+	layered bool
+}
+
+var _composedSourceIfaceCheck LookupSource = &composedSource{}
+var _composedSourceKeyIfaceCheck KeySource = &composedSource{}
+var _composedSourceSnapshotIfaceCheck SnapshotSource = &composedSource{}
+
+func (s *composedSource) Tag() string {
+	return s.tag
+}
+
+// Key returns the key of the source with the highest priority.
+func (s *composedSource) Key(tag TagValue) string {
+	if len(s.srcs) == 0 {
+		return tag.Name
+	}
+	if s.layered {
+		return sourceKey(s.srcs[len(s.srcs)-1], tag)
+	}
+	return sourceKey(s.srcs[0], tag)
+}
+
+func (s *composedSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *composedSource) Lookup(tag TagValue) (string, bool, error) {
+	for i := range s.srcs {
+		src := s.srcs[i]
+		if s.layered {
+			src = s.srcs[len(s.srcs)-1-i]
+		}
+		v, found, err := lookupValue(src, tag)
+		if err != nil {
+			return "", false, err
+		}
+		// Like the Loader, empty values only hide the ones of the lower layers with allowempty.
+		if v != "" || (found && s.layered && tag.HasFlag("allowempty")) {
+			return v, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Snapshot returns a copy of the source where each source is read from a single snapshot,
+// taken only if a value is needed from it.
+func (s *composedSource) Snapshot() (Source, error) {
+	srcs := make([]Source, len(s.srcs))
+	for i, src := range s.srcs {
+		srcs[i] = lazySnapshot(src)
+	}
+	return &composedSource{tag: s.tag, srcs: srcs, layered: s.layered}, nil
+}
+
+type watchableComposedSource struct {
+	*composedSource
+	watchable []WatchableSource
+}
+
+var _watchableComposedSourceIfaceCheck WatchableSource = &watchableComposedSource{}
+
+// Watch sends the events of all the watchable sources to events, until stop is closed
+// or any of them fails.
+func (s *watchableComposedSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	done := make(chan struct{})
+	var once sync.Once
+	halt := func() {
+		once.Do(func() { close(done) })
+	}
+	go func() {
+		select {
+		case <-stop:
+			halt()
+		case <-done:
+		}
+	}()
+	errc := make(chan error, len(s.watchable))
+	for _, ws := range s.watchable {
+		go func(ws WatchableSource) {
+			err := ws.Watch(done, events)
+			halt()
+			errc <- err
+		}(ws)
+	}
+	var err error
+	for range s.watchable {
+		if e := <-errc; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Alias creates a Source that reads the values from src for the fields tagged with tag
+// instead of the tag of src, e.g. Alias("secrets", NewFileSource("/run/secrets")).
+// The source is a WatchableSource if src is.
+func Alias(tag string, src Source) Source {
+	s := &aliasSource{Source: src, tag: tag}
+	if ws, ok := src.(WatchableSource); ok {
+		return &watchableAliasSource{aliasSource: s, ws: ws}
+	}
+	return s
+}
+
+type aliasSource struct {
+	Source
+	tag string
+}
+
+var _aliasSourceIfaceCheck LookupSource = &aliasSource{}
+var _aliasSourceKeyIfaceCheck KeySource = &aliasSource{}
+var _aliasSourceSnapshotIfaceCheck SnapshotSource = &aliasSource{}
+
+func (s *aliasSource) Tag() string {
+	return s.tag
+}
+
+func (s *aliasSource) Key(tag TagValue) string {
+	return sourceKey(s.Source, tag)
+}
+
+func (s *aliasSource) Lookup(tag TagValue) (string, bool, error) {
+	return lookupValue(s.Source, tag)
+}
+
+func (s *aliasSource) Snapshot() (Source, error) {
+	return &aliasSource{Source: lazySnapshot(s.Source), tag: s.tag}, nil
+}
+
+type watchableAliasSource struct {
+	*aliasSource
+	ws WatchableSource
+}
+
+var _watchableAliasSourceIfaceCheck WatchableSource = &watchableAliasSource{}
+
+func (s *watchableAliasSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	return s.ws.Watch(stop, events)
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFirstOfAndLayered(t *testing.T) {
+	dotenv := &testLookupSource{testSource{tag: "dotenv", values: map[string]string{
+		"HOST":  "dotenv-host",
+		"PORT":  "8080",
+		"DEBUG": "true",
+		"LEVEL": "debug",
+	}}}
+	env := &testLookupSource{testSource{tag: "env", values: map[string]string{
+		"HOST":  "env-host",
+		"DEBUG": "",
+		"LEVEL": "",
+	}}}
+	type settings struct {
+		Host  string `env:"HOST"`
+		Port  int    `env:"PORT"`
+		Debug string `env:"DEBUG,allowempty"`
+		Level string `env:"LEVEL"`
+		Name  string `env:"NAME" default:"app"`
+	}
+
+	testCases := []struct {
+		desc string
+		src  Source
+		out  settings
+	}{
+		{
+			desc: "first non-empty value",
+			src:  FirstOf("env", env, dotenv),
+			out:  settings{Host: "env-host", Port: 8080, Debug: "true", Level: "debug", Name: "app"},
+		},
+		{
+			desc: "last layer with a value",
+			src:  Layered("env", dotenv, env),
+			out:  settings{Host: "env-host", Port: 8080, Debug: "", Level: "debug", Name: "app"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if tC.src.Tag() != "env" {
+				t.Fatalf("expected tag to be '%s' but was '%s'", "env", tC.src.Tag())
+			}
+			var v settings
+			if err := NewLoader(tC.src).Load(&v); err != nil {
+				t.Fatalf("expected error to be nil but was '%s'", err.Error())
+			}
+			if !reflect.DeepEqual(tC.out, v) {
+				t.Errorf("expected output to be %v but was %v", tC.out, v)
+			}
+		})
+	}
+}
+
+func TestFirstOfErrors(t *testing.T) {
+	failing := &testSource{tag: "ssm", values: map[string]string{}}
+	backup := &testSource{tag: "ssm", values: map[string]string{"key": "value"}}
+	var v struct {
+		Key string `ssm:"key"`
+	}
+	err := NewLoader(FirstOf("ssm", backup, failing)).Load(&v)
+	if err != nil || v.Key != "value" {
+		t.Fatalf("expected 'value' without errors but was '%s' (%v)", v.Key, err)
+	}
+	err = NewLoader(FirstOf("ssm", failing, backup)).Load(&v)
+	expected := "config: error loading field Key for tag ssm: error getting key key"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error to be '%s' but was '%v'", expected, err)
+	}
+}
+
+func TestComposedSourceKey(t *testing.T) {
+	a := NewEnvSourceWithConfig(EnvSourceConfig{Prefix: "A_"})
+	b := NewEnvSourceWithConfig(EnvSourceConfig{Prefix: "B_"})
+	tag := newTagValue("PORT", "Port")
+	if k := sourceKey(FirstOf("env", a, b), tag); k != "A_PORT" {
+		t.Errorf("expected key to be '%s' but was '%s'", "A_PORT", k)
+	}
+	if k := sourceKey(Layered("env", a, b), tag); k != "B_PORT" {
+		t.Errorf("expected key to be '%s' but was '%s'", "B_PORT", k)
+	}
+	if k := sourceKey(Alias("app", a), tag); k != "A_PORT" {
+		t.Errorf("expected key to be '%s' but was '%s'", "A_PORT", k)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	src := &testLookupSource{testSource{tag: "test", values: map[string]string{"a": ""}}}
+	alias := Alias("other", src)
+	if alias.Tag() != "other" {
+		t.Fatalf("expected tag to be '%s' but was '%s'", "other", alias.Tag())
+	}
+	var v struct {
+		A string `other:"a,allowempty" test:"b" default:"default"`
+	}
+	if err := NewLoader(alias).Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if v.A != "" {
+		t.Fatalf("expected the empty value but was '%s'", v.A)
+	}
+	if _, ok := alias.(WatchableSource); ok {
+		t.Fatalf("expected the alias not to be watchable")
+	}
+
+	watched := &testWatchableSource{events: make(chan string)}
+	ws, ok := Alias("other", watched).(WatchableSource)
+	if !ok {
+		t.Fatalf("expected the alias to be watchable")
+	}
+	if ws.Tag() != "other" {
+		t.Fatalf("expected tag to be '%s' but was '%s'", "other", ws.Tag())
+	}
+	stop := make(chan struct{})
+	events := make(chan string)
+	done := make(chan error)
+	go func() { done <- ws.Watch(stop, events) }()
+	watched.events <- "a"
+	if name := <-events; name != "a" {
+		t.Fatalf("expected event for '%s' but was '%s'", "a", name)
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+}
+
+func TestComposedSourceWatch(t *testing.T) {
+	plain := &testSource{tag: "test", values: map[string]string{}}
+	if _, ok := Layered("test", plain, plain).(WatchableSource); ok {
+		t.Fatalf("expected the source not to be watchable")
+	}
+
+	a := &testWatchableSource{events: make(chan string)}
+	b := &testWatchableSource{events: make(chan string)}
+	for _, src := range []Source{FirstOf("test", a, plain, b), Layered("test", a, plain, b)} {
+		ws, ok := src.(WatchableSource)
+		if !ok {
+			t.Fatalf("expected the source to be watchable")
+		}
+		stop := make(chan struct{})
+		events := make(chan string)
+		done := make(chan error)
+		go func() { done <- ws.Watch(stop, events) }()
+		a.events <- "a"
+		if name := <-events; name != "a" {
+			t.Fatalf("expected event for '%s' but was '%s'", "a", name)
+		}
+		b.events <- "b"
+		if name := <-events; name != "b" {
+			t.Fatalf("expected event for '%s' but was '%s'", "b", name)
+		}
+		close(stop)
+		if err := <-done; err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConsulTag is the name of the tag to load values from the Consul KV store.
+const ConsulTag = "consul"
+
+// ConsulSourceConfig is the configuration for the creation of a Consul Source.
+type ConsulSourceConfig struct {
+	// Address of the Consul agent, e.g. http://127.0.0.1:8500. Defaults to the
+	// CONSUL_HTTP_ADDR environment variable or, if not set, to http://127.0.0.1:8500.
+	Address string
+	// Token authenticates the requests. Defaults to the CONSUL_HTTP_TOKEN environment variable.
+	Token string
+	// Prefix is prepended to every key, e.g. service/app/.
+	Prefix string
+	// Datacenter to read the keys from. Defaults to the datacenter of the agent.
+	Datacenter string
+	// Client sends the requests. Defaults to a client with a 10 seconds timeout.
+	Client *http.Client
+}
+
+// NewConsulSource creates a Source for values stored in the Consul KV store,
+// using the CONSUL_HTTP_ADDR and CONSUL_HTTP_TOKEN environment variables.
+func NewConsulSource() Source {
+	return NewConsulSourceWithConfig(ConsulSourceConfig{})
+}
+
+// NewConsulSourceWithConfig creates a Source for values stored in the Consul KV store specifying custom configuration.
+func NewConsulSourceWithConfig(cfg ConsulSourceConfig) Source {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if cfg.Address == "" {
+		cfg.Address = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(cfg.Address, "://") {
+		cfg.Address = "http://" + cfg.Address
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &consulSource{cfg: cfg}
+}
+
+type consulSource struct {
+	cfg ConsulSourceConfig
+}
+
+var _consulSourceIfaceCheck LookupSource = &consulSource{}
+var _consulSourceKeyIfaceCheck KeySource = &consulSource{}
+
+func (s *consulSource) Tag() string {
+	return ConsulTag
+}
+
+func (s *consulSource) Key(tag TagValue) string {
+	return s.cfg.Prefix + tag.Name
+}
+
+func (s *consulSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *consulSource) Lookup(tag TagValue) (string, bool, error) {
+	key := strings.TrimPrefix(s.Key(tag), "/")
+	u := strings.TrimRight(s.cfg.Address, "/") + "/v1/kv/" + escapeConsulKey(key)
+	if s.cfg.Datacenter != "" {
+		u += "?dc=" + url.QueryEscape(s.cfg.Datacenter)
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", false, err
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+	res, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return "", false, fmt.Errorf("config: consul returned %d for %s: %s", res.StatusCode, key, strings.TrimSpace(string(msg)))
+	}
+	var pairs []struct {
+		Key   string
+		Value *string
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pairs); err != nil {
+		return "", false, fmt.Errorf("config: invalid response from consul for %s: %v", key, err)
+	}
+	for _, p := range pairs {
+		if p.Key != key {
+			continue
+		}
+		if p.Value == nil {
+			return "", true, nil
+		}
+		v, err := base64.StdEncoding.DecodeString(*p.Value)
+		if err != nil {
+			return "", false, fmt.Errorf("config: invalid value in consul for %s: %v", key, err)
+		}
+		return string(v), true, nil
+	}
+	return "", false, nil
+}
+
+// escapeConsulKey escapes each segment of key, keeping the slashes that separate them.
+func escapeConsulKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
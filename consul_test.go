@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConsulSource(t *testing.T) {
+	values := map[string]map[string]*string{
+		"dc1": {
+			"service/app/max_conns": strPtr(base64.StdEncoding.EncodeToString([]byte("100"))),
+			"service/app/empty":     nil,
+			"service/app/invalid":   strPtr("not base64!"),
+			"service/app/a b?c#d%e": strPtr(base64.StdEncoding.EncodeToString([]byte("escaped"))),
+		},
+		"dc2": {
+			"service/app/max_conns": strPtr(base64.StdEncoding.EncodeToString([]byte("200"))),
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("ACL not found\n"))
+			return
+		}
+		dc := r.URL.Query().Get("dc")
+		if dc == "" {
+			dc = "dc1"
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		v, found := values[dc][key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		value := "null"
+		if v != nil {
+			value = `"` + *v + `"`
+		}
+		w.Write([]byte(`[{"Key":"` + key + `","Flags":0,"Value":` + value + `}]`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		desc  string
+		cfg   ConsulSourceConfig
+		name  string
+		out   string
+		found bool
+		err   string
+	}{
+		{
+			desc:  "decodes the value",
+			cfg:   ConsulSourceConfig{Token: "token"},
+			name:  "service/app/max_conns",
+			out:   "100",
+			found: true,
+		},
+		{
+			desc:  "prefix",
+			cfg:   ConsulSourceConfig{Token: "token", Prefix: "service/app/"},
+			name:  "max_conns",
+			out:   "100",
+			found: true,
+		},
+		{
+			desc:  "datacenter",
+			cfg:   ConsulSourceConfig{Token: "token", Datacenter: "dc2"},
+			name:  "service/app/max_conns",
+			out:   "200",
+			found: true,
+		},
+		{
+			desc:  "key without value",
+			cfg:   ConsulSourceConfig{Token: "token"},
+			name:  "service/app/empty",
+			out:   "",
+			found: true,
+		},
+		{
+			desc:  "escapes the key",
+			cfg:   ConsulSourceConfig{Token: "token"},
+			name:  "service/app/a b?c#d%e",
+			out:   "escaped",
+			found: true,
+		},
+		{
+			desc: "missing key",
+			cfg:  ConsulSourceConfig{Token: "token"},
+			name: "service/app/missing",
+		},
+		{
+			desc: "invalid value",
+			cfg:  ConsulSourceConfig{Token: "token"},
+			name: "service/app/invalid",
+			err:  "config: invalid value in consul for service/app/invalid: illegal base64 data at input byte 3",
+		},
+		{
+			desc: "invalid token",
+			cfg:  ConsulSourceConfig{Token: "invalid"},
+			name: "service/app/max_conns",
+			err:  "config: consul returned 403 for service/app/max_conns: ACL not found",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tC.cfg.Address = server.URL
+			s := NewConsulSourceWithConfig(tC.cfg)
+			if s.Tag() != "consul" {
+				t.Fatalf("expected tag to be '%s' but was '%s'", "consul", s.Tag())
+			}
+			out, found, err := s.(LookupSource).Lookup(newTagValue(tC.name, "Field"))
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if out != tC.out || found != tC.found {
+				t.Fatalf("expected '%s' (found: %v) but was '%s' (found: %v)", tC.out, tC.found, out, found)
+			}
+		})
+	}
+}
+
+func TestConsulSourceKey(t *testing.T) {
+	s := NewConsulSourceWithConfig(ConsulSourceConfig{Prefix: "service/app/"})
+	key := sourceKey(s, newTagValue("max_conns", "Field"))
+	if key != "service/app/max_conns" {
+		t.Fatalf("expected '%s' but was '%s'", "service/app/max_conns", key)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
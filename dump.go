@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// SecretTag is the name of the tag marking a field as secret: secret:"true".
+// Fields tagged with the ssm secure flag are secret as well.
+const SecretTag = "secret"
+
+// redacted replaces the values of secret fields.
+const redacted = "******"
+
+// FieldDescription describes the value of a loaded field.
+type FieldDescription struct {
+	// Path is the path of the field, e.g. DB.Host.
+	Path string `json:"path"`
+	// Value is the value of the field, masked if the field is secret.
+	Value string `json:"value"`
+	// Secret is true when the value has been masked.
+	Secret bool `json:"secret,omitempty"`
+	// Source describes where the value was loaded from, nil if unknown.
+	Source *FieldProvenance `json:"source,omitempty"`
+}
+
+// Describe returns the values of the fields of v, which must be a struct or a pointer
+// to a struct, with the values of secret fields masked. The provenance returned by
+// Loader.LoadWithProvenance is used to describe the source of each value, and can be nil.
+func Describe(v interface{}, p Provenance) ([]FieldDescription, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("config: v is not a struct")
+	}
+	var fields []FieldDescription
+	describeStruct(rv, "", p, &fields)
+	return fields, nil
+}
+
+func describeStruct(rv reflect.Value, path string, p Provenance, fields *[]FieldDescription) {
+	rt := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		ft := rt.Field(i)
+		if ft.PkgPath != "" {
+			continue
+		}
+		fieldPath := ft.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct {
+			describeStruct(fv, fieldPath, p, fields)
+			continue
+		}
+		d := FieldDescription{
+			Path:   fieldPath,
+			Value:  fmt.Sprint(fv.Interface()),
+			Secret: isSecret(ft),
+		}
+		if d.Secret && d.Value != "" {
+			d.Value = redacted
+		}
+		if fp, found := p[fieldPath]; found {
+			d.Source = &fp
+		}
+		*fields = append(*fields, d)
+	}
+}
+
+func isSecret(ft reflect.StructField) bool {
+	if ft.Tag.Get(SecretTag) == "true" {
+		return true
+	}
+	tagValue, found := ft.Tag.Lookup(SSMTag)
+	return found && newTagValue(tagValue, ft.Name).HasFlag("secure")
+}
+
+// Dump writes the values of the fields of v to w, one per line, with the values
+// of secret fields masked. See Describe.
+func Dump(w io.Writer, v interface{}, p Provenance) error {
+	fields, err := Describe(v, p)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		line := fmt.Sprintf("%s = %s", f.Path, f.Value)
+		if f.Source != nil {
+			line += fmt.Sprintf(" (%s)", describeSource(*f.Source))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeSource(fp FieldProvenance) string {
+	var s string
+	switch {
+	case fp.Tag != "":
+		s = fmt.Sprintf("%s: %s", fp.Tag, fp.Key)
+	case fp.Default:
+		s = "default"
+	default:
+		s = "not set"
+	}
+	if len(fp.Overridden) > 0 {
+		s += ", overrides " + strings.Join(fp.Overridden, ", ")
+	}
+	return s
+}
+
+// DumpJSON writes the values of the fields of v to w as a JSON array, with the
+// values of secret fields masked. See Describe.
+func DumpJSON(w io.Writer, v interface{}, p Provenance) error {
+	fields, err := Describe(v, p)
+	if err != nil {
+		return err
+	}
+	if fields == nil {
+		fields = []FieldDescription{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fields)
+}
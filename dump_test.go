@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+type testDumpSettings struct {
+	Host     string `env:"HOST"`
+	Port     int    `env:"PORT" default:"80"`
+	Password string `env:"PASSWORD" secret:"true"`
+	APIKey   string `ssm:"api_key,secure" env:"API_KEY"`
+	Token    string `env:"TOKEN" secret:"true" default:""`
+	Other    string
+	internal string
+	DB       struct {
+		Name string `env:"DB_NAME"`
+	}
+}
+
+func testDumpValues() (*testDumpSettings, Provenance) {
+	v := &testDumpSettings{
+		Host:     "localhost",
+		Port:     80,
+		Password: "s3cret",
+		APIKey:   "key",
+		Other:    "other",
+		internal: "internal",
+	}
+	v.DB.Name = "db"
+	p := Provenance{
+		"Host":     {Tag: "env", Key: "HOST"},
+		"Port":     {Default: true},
+		"Password": {Tag: "env", Key: "PASSWORD"},
+		"APIKey":   {Tag: "env", Key: "API_KEY", Overridden: []string{"ssm"}},
+		"Token":    {Default: true},
+		"DB.Name":  {Tag: "env", Key: "DB_NAME"},
+	}
+	return v, p
+}
+
+func TestDump(t *testing.T) {
+	v, p := testDumpValues()
+	var buf bytes.Buffer
+	err := Dump(&buf, v, p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `Host = localhost (env: HOST)
+Port = 80 (default)
+Password = ****** (env: PASSWORD)
+APIKey = ****** (env: API_KEY, overrides ssm)
+Token =  (default)
+Other = other
+DB.Name = db (env: DB_NAME)
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	v, p := testDumpValues()
+	var buf bytes.Buffer
+	err := DumpJSON(&buf, *v, p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `[
+  {
+    "path": "Host",
+    "value": "localhost",
+    "source": {
+      "tag": "env",
+      "key": "HOST"
+    }
+  },
+  {
+    "path": "Port",
+    "value": "80",
+    "source": {
+      "default": true
+    }
+  },
+  {
+    "path": "Password",
+    "value": "******",
+    "secret": true,
+    "source": {
+      "tag": "env",
+      "key": "PASSWORD"
+    }
+  },
+  {
+    "path": "APIKey",
+    "value": "******",
+    "secret": true,
+    "source": {
+      "tag": "env",
+      "key": "API_KEY",
+      "overridden": [
+        "ssm"
+      ]
+    }
+  },
+  {
+    "path": "Token",
+    "value": "",
+    "secret": true,
+    "source": {
+      "default": true
+    }
+  },
+  {
+    "path": "Other",
+    "value": "other"
+  },
+  {
+    "path": "DB.Name",
+    "value": "db",
+    "source": {
+      "tag": "env",
+      "key": "DB_NAME"
+    }
+  }
+]
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestDescribeNotAStruct(t *testing.T) {
+	s := "test"
+	_, err := Describe(&s, nil)
+	if err == nil {
+		t.Fatal("expected to get an error, got nil")
+	}
+	if err.Error() != "config: v is not a struct" {
+		t.Errorf("unexpected error: '%s'", err.Error())
+	}
+}
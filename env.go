@@ -1,18 +1,95 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
+)
 
 // EnvTag is the name of the tag to load variables from the environment.
 const EnvTag = "env"
 
+// EnvFileSuffix is appended to the name of a variable to find the file containing
+// its value, e.g. DB_PASSWORD_FILE=/run/secrets/db for DB_PASSWORD.
+const EnvFileSuffix = "_FILE"
+
 // NewEnvSource creates a new Source for the current environment.
 func NewEnvSource() Source {
-	return &source{
-		tag: EnvTag,
-		get: loadFromEnv,
+	return NewEnvSourceWithConfig(EnvSourceConfig{})
+}
+
+// EnvSourceConfig is the configuration for the creation of an environment Source.
+type EnvSourceConfig struct {
+	// FileIndirection reads the value of every variable that is not set from the file
+	// referenced by the same variable with EnvFileSuffix. Use the file flag to enable
+	// it for a single field instead: env:"DB_PASSWORD,file".
+	FileIndirection bool
+	// Prefix is prepended to the name of every variable, e.g. MYAPP_.
+	Prefix string
+	// Lookup retrieves the value of a variable. Defaults to os.LookupEnv.
+	Lookup func(key string) (string, bool)
+}
+
+// NewEnvSourceWithConfig creates a new Source for the current environment specifying custom configuration.
+func NewEnvSourceWithConfig(cfg EnvSourceConfig) Source {
+	if cfg.Lookup == nil {
+		cfg.Lookup = os.LookupEnv
+	}
+	return &envSource{
+		cfg: cfg,
+	}
+}
+
+type envSource struct {
+	cfg EnvSourceConfig
+}
+
+var _envSourceIfaceCheck LookupSource = &envSource{}
+var _envSourceKeyIfaceCheck KeySource = &envSource{}
+
+func (s *envSource) Tag() string {
+	return EnvTag
+}
+
+func (s *envSource) Key(tag TagValue) string {
+	return s.cfg.Prefix + tag.Name
+}
+
+func (s *envSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *envSource) Lookup(tag TagValue) (string, bool, error) {
+	name := s.Key(tag)
+	v, found := s.cfg.Lookup(name)
+	if found || !(s.cfg.FileIndirection || tag.HasFlag("file")) {
+		return v, found, nil
+	}
+	fileVar := name + EnvFileSuffix
+	path, _ := s.cfg.Lookup(fileVar)
+	if path == "" {
+		return v, found, nil
+	}
+	v, err := readValueFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("config: error reading %s: %v", fileVar, err)
 	}
+	return v, true, nil
 }
 
-func loadFromEnv(tag TagValue) (string, error) {
-	return os.Getenv(tag.Name), nil
+// EnvironLookup creates a lookup function for a snapshot of the environment
+// in the form returned by os.Environ, i.e. "key=value".
+func EnvironLookup(environ []string) func(key string) (string, bool) {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		bits := strings.SplitN(kv, "=", 2)
+		if len(bits) == 2 {
+			vars[bits[0]] = bits[1]
+		}
+	}
+	return func(key string) (string, bool) {
+		v, found := vars[key]
+		return v, found
+	}
 }
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestEnvSource(t *testing.T) {
-	s := NewEnvSource()
+	t.Parallel()
+	s := NewEnvSourceWithConfig(EnvSourceConfig{
+		Lookup: EnvironLookup([]string{"testenv=testvalue"}),
+	})
 	if s.Tag() != "env" {
 		t.Errorf("expected tag to be '%s' but was '%s'", "env", s.Tag())
 	}
@@ -22,10 +27,6 @@ func TestEnvSource(t *testing.T) {
 	}
 
 	// Value set in the enviornment.
-	err = os.Setenv("testenv", "testvalue")
-	if err != nil {
-		t.Fatalf("unexpected error setting env variable: %v", err)
-	}
 	tag = TagValue{Name: "testenv"}
 	v, err = s.Get(tag)
 	if err != nil {
@@ -35,3 +36,157 @@ func TestEnvSource(t *testing.T) {
 		t.Errorf("expected value to be '%s' but was '%s'", "testvalue", v)
 	}
 }
+
+func TestEnvSourceLookup(t *testing.T) {
+	t.Parallel()
+	s := NewEnvSourceWithConfig(EnvSourceConfig{
+		Lookup: EnvironLookup([]string{"EMPTY=", "SET=value"}),
+	}).(LookupSource)
+	testCases := []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{key: "EMPTY", value: "", found: true},
+		{key: "SET", value: "value", found: true},
+		{key: "UNSET", value: "", found: false},
+	}
+	for _, tC := range testCases {
+		v, found, err := s.Lookup(TagValue{Name: tC.key})
+		if err != nil {
+			t.Fatalf("unexpected error getting value for key '%s': %v", tC.key, err)
+		}
+		if v != tC.value || found != tC.found {
+			t.Errorf("expected %s to be ('%s', %v) but was ('%s', %v)", tC.key, tC.value, tC.found, v, found)
+		}
+	}
+}
+
+func TestEnvSourcePrefix(t *testing.T) {
+	t.Parallel()
+	s := NewEnvSourceWithConfig(EnvSourceConfig{
+		Prefix: "MYAPP_",
+		Lookup: EnvironLookup([]string{"PORT=80", "MYAPP_PORT=8080"}),
+	})
+	v, err := s.Get(TagValue{Name: "PORT"})
+	if err != nil {
+		t.Fatalf("unexpected error getting value for key '%s': %v", "PORT", err)
+	}
+	if v != "8080" {
+		t.Errorf("expected value to be '%s' but was '%s'", "8080", v)
+	}
+}
+
+func TestEnvironLookup(t *testing.T) {
+	t.Parallel()
+	lookup := EnvironLookup([]string{"A=1", "B=", "C=x=y", "invalid"})
+	testCases := []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{key: "A", value: "1", found: true},
+		{key: "B", value: "", found: true},
+		{key: "C", value: "x=y", found: true},
+		{key: "invalid", value: "", found: false},
+		{key: "D", value: "", found: false},
+	}
+	for _, tC := range testCases {
+		v, found := lookup(tC.key)
+		if v != tC.value || found != tC.found {
+			t.Errorf("expected %s to be ('%s', %v) but was ('%s', %v)", tC.key, tC.value, tC.found, v, found)
+		}
+	}
+}
+
+func TestEnvSourceFileIndirection(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db")
+	mustWriteFile(t, path, "s3cret\n")
+
+	lookup := EnvironLookup([]string{
+		"testenv_direct=direct",
+		"testenv_direct_FILE=" + path,
+		"testenv_empty=",
+		"testenv_empty_FILE=" + path,
+		"testenv_indirect_FILE=" + path,
+		"testenv_broken_FILE=" + filepath.Join(dir, "missing"),
+		"APP_testenv_prefixed_FILE=" + path,
+	})
+
+	testCases := []struct {
+		desc string
+		cfg  EnvSourceConfig
+		tag  TagValue
+		out  string
+		err  string
+	}{
+		{
+			desc: "disabled",
+			tag:  TagValue{Name: "testenv_indirect"},
+			out:  "",
+		},
+		{
+			desc: "enabled with the file flag",
+			tag:  newTagValue("testenv_indirect,file", ""),
+			out:  "s3cret",
+		},
+		{
+			desc: "enabled for all variables",
+			cfg:  EnvSourceConfig{FileIndirection: true},
+			tag:  TagValue{Name: "testenv_indirect"},
+			out:  "s3cret",
+		},
+		{
+			desc: "variable takes precedence over the file",
+			cfg:  EnvSourceConfig{FileIndirection: true},
+			tag:  TagValue{Name: "testenv_direct"},
+			out:  "direct",
+		},
+		{
+			desc: "empty variable takes precedence over the file",
+			cfg:  EnvSourceConfig{FileIndirection: true},
+			tag:  TagValue{Name: "testenv_empty"},
+			out:  "",
+		},
+		{
+			desc: "neither variable is set",
+			cfg:  EnvSourceConfig{FileIndirection: true},
+			tag:  TagValue{Name: "testenv_unset"},
+			out:  "",
+		},
+		{
+			desc: "prefix applies to the file variable",
+			cfg:  EnvSourceConfig{FileIndirection: true, Prefix: "APP_"},
+			tag:  TagValue{Name: "testenv_prefixed"},
+			out:  "s3cret",
+		},
+		{
+			desc: "file can't be read",
+			cfg:  EnvSourceConfig{FileIndirection: true},
+			tag:  TagValue{Name: "testenv_broken"},
+			err:  "config: error reading testenv_broken_FILE: stat " + filepath.Join(dir, "missing") + ": no such file or directory",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tC.cfg.Lookup = lookup
+			v, err := NewEnvSourceWithConfig(tC.cfg).Get(tC.tag)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if v != tC.out {
+				t.Errorf("expected value to be '%s' but was '%s'", tC.out, v)
+			}
+		})
+	}
+}
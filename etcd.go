@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+// EtcdTag is the name of the tag to load values from etcd.
+const EtcdTag = "etcd"
+
+// EtcdClient is the subset of the etcd v3 API used by the etcd source.
+// It can be implemented on top of go.etcd.io/etcd/client/v3, e.g.
+//
+//	func (c client) Get(key string, prefix bool) (map[string]string, error) {
+//		var opts []clientv3.OpOption
+//		if prefix {
+//			opts = append(opts, clientv3.WithPrefix())
+//		}
+//		res, err := c.KV.Get(context.Background(), key, opts...)
+//		...
+//	}
+type EtcdClient interface {
+	// Get returns the value of key or, if prefix is true, the values of all the
+	// keys starting with key, indexed by the full key.
+	Get(key string, prefix bool) (map[string]string, error)
+	// Watch sends the keys starting with prefix that change to keys,
+	// until stop is closed.
+	Watch(stop <-chan struct{}, prefix string, keys chan<- string) error
+}
+
+// EtcdSourceConfig is the configuration for the creation of an etcd Source.
+type EtcdSourceConfig struct {
+	Client EtcdClient
+	// Prefix is prepended to every key, e.g. /config/app/.
+	Prefix string
+	// Prefetch reads all the keys starting with Prefix with a single range request
+	// per load, instead of a request for each key.
+	Prefetch bool
+	// Watch makes the source a WatchableSource, notifying the keys
+	// starting with Prefix that change.
+	Watch bool
+}
+
+// NewEtcdSource creates a Source for values stored in etcd.
+func NewEtcdSource(client EtcdClient) Source {
+	return NewEtcdSourceWithConfig(EtcdSourceConfig{Client: client})
+}
+
+// NewEtcdSourceWithConfig creates a Source for values stored in etcd specifying custom configuration.
+func NewEtcdSourceWithConfig(cfg EtcdSourceConfig) Source {
+	s := &etcdSource{cfg: cfg}
+	if cfg.Watch {
+		return &etcdWatchSource{s}
+	}
+	return s
+}
+
+type etcdSource struct {
+	cfg EtcdSourceConfig
+}
+
+// etcdWatchSource is an etcd source that notifies the changes.
+type etcdWatchSource struct {
+	*etcdSource
+}
+
+var _etcdSourceIfaceCheck LookupSource = &etcdSource{}
+var _etcdSourceKeyIfaceCheck KeySource = &etcdSource{}
+var _etcdSourceSnapshotIfaceCheck SnapshotSource = &etcdSource{}
+var _etcdWatchSourceIfaceCheck WatchableSource = &etcdWatchSource{}
+
+func (s *etcdSource) Tag() string {
+	return EtcdTag
+}
+
+func (s *etcdSource) Key(tag TagValue) string {
+	return s.cfg.Prefix + tag.Name
+}
+
+func (s *etcdSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *etcdSource) Lookup(tag TagValue) (string, bool, error) {
+	if s.cfg.Prefetch {
+		snap, err := s.Snapshot()
+		if err != nil {
+			return "", false, err
+		}
+		return lookupValue(snap, tag)
+	}
+	if s.cfg.Client == nil {
+		return "", false, errors.New("config: etcd client is not set")
+	}
+	key := s.Key(tag)
+	values, err := s.cfg.Client.Get(key, false)
+	if err != nil {
+		return "", false, err
+	}
+	v, found := values[key]
+	return v, found, nil
+}
+
+// Snapshot reads all the keys starting with the prefix with a single range request
+// if Prefetch is set, and returns a Source for their values.
+func (s *etcdSource) Snapshot() (Source, error) {
+	if !s.cfg.Prefetch {
+		return s, nil
+	}
+	if s.cfg.Client == nil {
+		return nil, errors.New("config: etcd client is not set")
+	}
+	values, err := s.cfg.Client.Get(s.cfg.Prefix, true)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(values))
+	for k, v := range values {
+		if strings.HasPrefix(k, s.cfg.Prefix) {
+			names[strings.TrimPrefix(k, s.cfg.Prefix)] = v
+		}
+	}
+	return NewMapSource(EtcdTag, names), nil
+}
+
+// Watch sends the names of the keys that change to events.
+func (s *etcdWatchSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	keys := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.cfg.Client.Watch(stop, s.cfg.Prefix, keys)
+	}()
+	for {
+		select {
+		case key := <-keys:
+			select {
+			case events <- strings.TrimPrefix(key, s.cfg.Prefix):
+			case <-stop:
+			}
+		case err := <-errc:
+			return err
+		}
+	}
+}
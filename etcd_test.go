@@ -0,0 +1,187 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testEtcd struct {
+	mu       sync.Mutex
+	values   map[string]string
+	requests int
+	err      error
+	changes  chan string
+}
+
+func (c *testEtcd) Get(key string, prefix bool) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if c.err != nil {
+		return nil, c.err
+	}
+	values := map[string]string{}
+	for k, v := range c.values {
+		if k == key || (prefix && strings.HasPrefix(k, key)) {
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+func (c *testEtcd) Watch(stop <-chan struct{}, prefix string, keys chan<- string) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		case key := <-c.changes:
+			if strings.HasPrefix(key, prefix) {
+				keys <- key
+			}
+		}
+	}
+}
+
+func (c *testEtcd) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+}
+
+func TestEtcdSource(t *testing.T) {
+	client := &testEtcd{values: map[string]string{
+		"/config/app/host":  "localhost",
+		"/config/app/empty": "",
+		"/config/other":     "other",
+	}}
+
+	testCases := []struct {
+		desc     string
+		cfg      EtcdSourceConfig
+		requests int
+	}{
+		{
+			desc:     "a request for each key",
+			cfg:      EtcdSourceConfig{Prefix: "/config/app/"},
+			requests: 3,
+		},
+		{
+			desc:     "a range request for the prefix",
+			cfg:      EtcdSourceConfig{Prefix: "/config/app/", Prefetch: true},
+			requests: 1,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			client.requests = 0
+			tC.cfg.Client = client
+			s := NewEtcdSourceWithConfig(tC.cfg)
+			if s.Tag() != "etcd" {
+				t.Fatalf("expected tag to be '%s' but was '%s'", "etcd", s.Tag())
+			}
+			type settings struct {
+				Host  string `etcd:"host"`
+				Empty string `etcd:"empty,allowempty" default:"default"`
+				Other string `etcd:"other" default:"default"`
+			}
+			var v settings
+			if err := NewLoader(s).Load(&v); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out := (settings{Host: "localhost", Other: "default"}); !reflect.DeepEqual(out, v) {
+				t.Fatalf("expected output to be %v but was %v", out, v)
+			}
+			if client.requests != tC.requests {
+				t.Fatalf("expected %d requests but was %d", tC.requests, client.requests)
+			}
+			if k := sourceKey(s, newTagValue("host", "Field")); k != "/config/app/host" {
+				t.Fatalf("expected key to be '%s' but was '%s'", "/config/app/host", k)
+			}
+		})
+	}
+}
+
+func TestEtcdSourcePrefetch(t *testing.T) {
+	client := &testEtcd{values: map[string]string{"/app/host": "localhost"}}
+	s := NewEtcdSourceWithConfig(EtcdSourceConfig{Client: client, Prefix: "/app/", Prefetch: true})
+	l := NewLoader(s)
+	var v struct {
+		Host string `etcd:"host"`
+	}
+	if err := l.Load(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Every load reads the keys again.
+	client.set("/app/host", "remote")
+	if err := l.Load(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Host != "remote" || client.requests != 2 {
+		t.Fatalf("expected 'remote' after 2 requests but was '%s' after %d", v.Host, client.requests)
+	}
+
+	// Reading without a Loader sends a request for each key.
+	out, err := s.Get(newTagValue("host", "Field"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "remote" || client.requests != 3 {
+		t.Fatalf("expected 'remote' after 3 requests but was '%s' after %d", out, client.requests)
+	}
+}
+
+func TestEtcdSourceErrors(t *testing.T) {
+	s := NewEtcdSource(nil)
+	if _, err := s.Get(newTagValue("host", "Field")); err == nil || err.Error() != "config: etcd client is not set" {
+		t.Fatalf("expected error to be 'config: etcd client is not set' but was '%v'", err)
+	}
+	s = NewEtcdSource(&testEtcd{err: errors.New("unavailable")})
+	if _, err := s.Get(newTagValue("host", "Field")); err == nil || err.Error() != "unavailable" {
+		t.Fatalf("expected error to be 'unavailable' but was '%v'", err)
+	}
+	if _, ok := s.(WatchableSource); ok {
+		t.Fatalf("expected the source not to be watchable")
+	}
+}
+
+func TestEtcdSourceWatch(t *testing.T) {
+	client := &testEtcd{
+		values:  map[string]string{"/app/host": "localhost"},
+		changes: make(chan string),
+	}
+	type settings struct {
+		Host string `etcd:"host"`
+	}
+	changes := make(chan []Change, 1)
+	l := NewLoader(NewEtcdSourceWithConfig(EtcdSourceConfig{
+		Client:   client,
+		Prefix:   "/app/",
+		Prefetch: true,
+		Watch:    true,
+	}))
+	w, err := NewWatcher(l, &settings{}, WatcherConfig{
+		OnChange: func(c []Change) { changes <- c },
+		OnError:  func(err error) { t.Errorf("unexpected error: %v", err) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	client.set("/app/host", "remote")
+	client.changes <- "/other/host"
+	client.changes <- "/app/host"
+	select {
+	case c := <-changes:
+		if len(c) != 1 || c[0].Path != "Host" || c[0].New != "remote" {
+			t.Fatalf("unexpected changes: %v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected a change")
+	}
+}
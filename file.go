@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileTag is the name of the tag to load values from files in a directory.
+const FileTag = "file"
+
+// NewFileSource creates a new Source that loads values from the files in dir,
+// one file per key. This is how Kubernetes secret volumes and Docker secrets
+// (e.g. /run/secrets) are exposed to a container.
+func NewFileSource(dir string) Source {
+	return &fileSource{
+		dir: dir,
+	}
+}
+
+type fileSource struct {
+	dir string
+}
+
+var _fileSourceIfaceCheck LookupSource = &fileSource{}
+var _fileSourceWatchIfaceCheck WatchableSource = &fileSource{}
+
+func (s *fileSource) Tag() string {
+	return FileTag
+}
+
+func (s *fileSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *fileSource) Lookup(tag TagValue) (string, bool, error) {
+	path, err := resolveFile(s.dir, tag.Name)
+	if err != nil || path == "" {
+		return "", false, err
+	}
+	v, err := readValueFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+func (s *fileSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	return watchDir(s.dir, stop, events)
+}
+
+// listFiles returns the names of the files in dir, skipping the hidden
+// entries such as the ..data directory of Kubernetes.
+func listFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Name(), ".") {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+// sendEvents sends the names to events, returning false if stop is closed first.
+func sendEvents(names []string, stop <-chan struct{}, events chan<- string) bool {
+	for _, name := range names {
+		select {
+		case events <- name:
+		case <-stop:
+			return false
+		}
+	}
+	return true
+}
+
+// resolveFile returns the path of the file for name within dir, following
+// symlinks such as the ones Kubernetes creates through the ..data directory.
+// An empty path is returned when the file doesn't exist.
+func resolveFile(dir, name string) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(rel) || isParentPath(rel) {
+		return "", fmt.Errorf("config: file %s is outside of %s", name, dir)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, rel))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	// Symlinks can still point anywhere on the filesystem,
+	// so check where the file actually is.
+	rel, err = filepath.Rel(root, path)
+	if err != nil || isParentPath(rel) {
+		return "", fmt.Errorf("config: file %s is outside of %s", name, dir)
+	}
+	return path, nil
+}
+
+func isParentPath(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func readValueFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("config: %s is a directory", path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "secrets")
+	outside := filepath.Join(dir, "outside")
+	// Kubernetes layout: root/key -> ..data/key, root/..data -> ..2020_06_01
+	mustWriteFile(t, filepath.Join(root, "..2020_06_01", "db_password"), "s3cret\n")
+	mustWriteFile(t, filepath.Join(root, "plain"), "value\r\n")
+	mustWriteFile(t, outside, "leaked")
+	mustSymlink(t, "..2020_06_01", filepath.Join(root, "..data"))
+	mustSymlink(t, filepath.Join("..data", "db_password"), filepath.Join(root, "db_password"))
+	mustSymlink(t, outside, filepath.Join(root, "escape"))
+	if err := os.Mkdir(filepath.Join(root, "dir"), 0700); err != nil {
+		t.Fatalf("unexpected error creating dir: %v", err)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatalf("unexpected error resolving root: %v", err)
+	}
+
+	s := NewFileSource(root)
+	if s.Tag() != "file" {
+		t.Errorf("expected tag to be '%s' but was '%s'", "file", s.Tag())
+	}
+
+	testCases := []struct {
+		desc string
+		name string
+		out  string
+		err  string
+	}{
+		{
+			desc: "follows the ..data symlinks",
+			name: "db_password",
+			out:  "s3cret",
+		},
+		{
+			desc: "trims trailing newlines",
+			name: "plain",
+			out:  "value",
+		},
+		{
+			desc: "missing file",
+			name: "missing",
+			out:  "",
+		},
+		{
+			desc: "relative path outside of root",
+			name: "../outside",
+			err:  "config: file ../outside is outside of " + root,
+		},
+		{
+			desc: "absolute path",
+			name: outside,
+			err:  "config: file " + outside + " is outside of " + root,
+		},
+		{
+			desc: "symlink outside of root",
+			name: "escape",
+			err:  "config: file escape is outside of " + root,
+		},
+		{
+			desc: "directory",
+			name: "dir",
+			err:  "config: " + filepath.Join(realRoot, "dir") + " is a directory",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			v, err := s.Get(TagValue{Name: tC.name})
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if v != tC.out {
+				t.Errorf("expected value to be '%s' but was '%s'", tC.out, v)
+			}
+		})
+	}
+}
+
+func TestFileSourceMissingDir(t *testing.T) {
+	s := NewFileSource(filepath.Join(os.TempDir(), "config-does-not-exist"))
+	v, err := s.Get(TagValue{Name: "key"})
+	if err != nil {
+		t.Fatalf("unexpected error getting value: %v", err)
+	}
+	if v != "" {
+		t.Errorf("expected value to be '%s' but was '%s'", "", v)
+	}
+}
+
+func mustWriteFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("unexpected error creating dir: %v", err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error writing file: %v", err)
+	}
+}
+
+func mustSymlink(t *testing.T, oldname, newname string) {
+	t.Helper()
+	if err := os.Symlink(oldname, newname); err != nil {
+		t.Fatalf("unexpected error creating symlink: %v", err)
+	}
+}
+
+func TestFileSourceWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("unexpected error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	mustWriteFile(t, filepath.Join(dir, "..2020_06_01", "a"), "1")
+	mustWriteFile(t, filepath.Join(dir, "..2020_06_01", "b"), "1")
+	mustSymlink(t, "..2020_06_01", filepath.Join(dir, "..data"))
+	mustSymlink(t, filepath.Join("..data", "a"), filepath.Join(dir, "a"))
+	mustSymlink(t, filepath.Join("..data", "b"), filepath.Join(dir, "b"))
+
+	s := NewFileSource(dir).(WatchableSource)
+	stop := make(chan struct{})
+	events := make(chan string)
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(stop, events)
+	}()
+	// Give the source time to start watching.
+	time.Sleep(50 * time.Millisecond)
+
+	waitEvents := func(out ...string) {
+		t.Helper()
+		received := map[string]bool{}
+		for _, name := range out {
+			for !received[name] {
+				select {
+				case name := <-events:
+					received[name] = true
+				case <-time.After(5 * time.Second):
+					t.Fatalf("expected events for %v but got %v", out, received)
+				}
+			}
+		}
+	}
+
+	mustWriteFile(t, filepath.Join(dir, "c"), "1")
+	waitEvents("c")
+
+	// Kubernetes updates all the files atomically by replacing the ..data symlink.
+	mustWriteFile(t, filepath.Join(dir, "..2020_06_02", "a"), "2")
+	mustWriteFile(t, filepath.Join(dir, "..2020_06_02", "b"), "2")
+	mustSymlink(t, "..2020_06_02", filepath.Join(dir, "..data_tmp"))
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("unexpected error renaming: %v", err)
+	}
+	waitEvents("a", "b")
+
+	close(stop)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Watch to return")
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// watchDir watches dir with inotify. Changes to hidden entries, which is how
+// Kubernetes atomically replaces all the files through the ..data symlink,
+// are reported as changes to every file.
+func watchDir(dir string, stop <-chan struct{}, events chan<- string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	// The file is non-blocking, so that closing it interrupts Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	const mask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_CLOSE_WRITE |
+		syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_ATTRIB
+	_, err = syscall.InotifyAddWatch(fd, dir, mask)
+	if err != nil {
+		f.Close()
+		return os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		f.Close()
+	}()
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := f.Read(buf)
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		names, err := inotifyNames(dir, buf[:n])
+		if err != nil {
+			return err
+		}
+		if !sendEvents(names, stop, events) {
+			return nil
+		}
+	}
+}
+
+// inotifyNames returns the names of the files changed by the inotify events in buf.
+func inotifyNames(dir string, buf []byte) ([]string, error) {
+	var names []string
+	all := false
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		start := offset + syscall.SizeofInotifyEvent
+		offset = start + int(ev.Len)
+		name := strings.TrimRight(string(buf[start:offset]), "\x00")
+		if name == "" {
+			continue
+		}
+		if strings.HasPrefix(name, ".") {
+			all = true
+			continue
+		}
+		names = append(names, name)
+	}
+	if all {
+		return listFiles(dir)
+	}
+	return names, nil
+}
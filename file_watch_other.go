@@ -0,0 +1,57 @@
+//go:build !linux
+// +build !linux
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// filePollInterval is the interval between checks of the files on the
+// platforms where inotify is not available.
+var filePollInterval = time.Second
+
+// watchDir polls the files in dir, reporting the ones whose modification
+// time or size changed, and the ones that were created or deleted.
+func watchDir(dir string, stop <-chan struct{}, events chan<- string) error {
+	prev := statFiles(dir)
+	t := time.NewTicker(filePollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-t.C:
+		}
+		next := statFiles(dir)
+		var names []string
+		for name, info := range next {
+			if p, found := prev[name]; !found || !p.ModTime().Equal(info.ModTime()) || p.Size() != info.Size() {
+				names = append(names, name)
+			}
+		}
+		for name := range prev {
+			if _, found := next[name]; !found {
+				names = append(names, name)
+			}
+		}
+		prev = next
+		if !sendEvents(names, stop, events) {
+			return nil
+		}
+	}
+}
+
+func statFiles(dir string) map[string]os.FileInfo {
+	infos := make(map[string]os.FileInfo)
+	names, _ := listFiles(dir)
+	for _, name := range names {
+		// Stat follows the symlinks, e.g. to the ..data directory of Kubernetes.
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			infos[name] = info
+		}
+	}
+	return infos
+}
@@ -0,0 +1,85 @@
+// Package gcpsecret provides a source for values stored in Google Cloud Secret Manager.
+package gcpsecret
+
+import (
+	"errors"
+	"strings"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+// Tag is the name of the tag to load values from Secret Manager:
+// gcpsecret:"projects/p/secrets/s/versions/latest".
+const Tag = "gcpsecret"
+
+// ErrNotFound is returned by a Client when the secret version doesn't exist,
+// which is treated as an unset value.
+var ErrNotFound = errors.New("gcpsecret: secret not found")
+
+// Client accesses secret versions. It can be implemented on top of
+// cloud.google.com/go/secretmanager/apiv1, returning ErrNotFound when the
+// request fails with codes.NotFound.
+type Client interface {
+	// AccessSecretVersion returns the payload of the secret version with the given
+	// resource name, e.g. projects/p/secrets/s/versions/latest.
+	AccessSecretVersion(name string) ([]byte, error)
+}
+
+// Config is the configuration for the creation of a Secret Manager Source.
+type Config struct {
+	Client Client
+	// Project allows short keys: gcpsecret:"s" is the latest version of
+	// projects/<Project>/secrets/s, gcpsecret:"s/versions/3" is its version 3.
+	Project string
+}
+
+// NewSource creates a Source for values stored in Secret Manager.
+func NewSource(client Client) config.Source {
+	return NewSourceWithConfig(Config{Client: client})
+}
+
+// NewSourceWithConfig creates a Source for values stored in Secret Manager specifying custom configuration.
+func NewSourceWithConfig(cfg Config) config.Source {
+	return &source{cfg: cfg}
+}
+
+type source struct {
+	cfg Config
+}
+
+var _sourceIfaceCheck config.LookupSource = &source{}
+var _sourceKeyIfaceCheck config.KeySource = &source{}
+
+func (s *source) Tag() string {
+	return Tag
+}
+
+func (s *source) Key(tag config.TagValue) string {
+	name := tag.Name
+	if s.cfg.Project != "" && !strings.HasPrefix(name, "projects/") {
+		name = "projects/" + s.cfg.Project + "/secrets/" + name
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	return name
+}
+
+func (s *source) Get(tag config.TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *source) Lookup(tag config.TagValue) (string, bool, error) {
+	if s.cfg.Client == nil {
+		return "", false, errors.New("gcpsecret: client is not set")
+	}
+	payload, err := s.cfg.Client.AccessSecretVersion(s.Key(tag))
+	if errors.Is(err, ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(payload), true, nil
+}
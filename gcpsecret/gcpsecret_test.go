@@ -0,0 +1,83 @@
+package gcpsecret
+
+import (
+	"errors"
+	"testing"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+type fakeClient map[string]string
+
+func (c fakeClient) AccessSecretVersion(name string) ([]byte, error) {
+	if name == "projects/p/secrets/broken/versions/latest" {
+		return nil, errors.New("permission denied")
+	}
+	v, found := c[name]
+	if !found {
+		return nil, ErrNotFound
+	}
+	return []byte(v), nil
+}
+
+func TestSource(t *testing.T) {
+	client := fakeClient{
+		"projects/p/secrets/db/versions/latest":  "s3cret",
+		"projects/p/secrets/db/versions/1":       "old",
+		"projects/q/secrets/api/versions/latest": "key",
+	}
+	l := config.NewLoader(NewSourceWithConfig(Config{Client: client, Project: "p"}))
+
+	var settings struct {
+		Full    string `gcpsecret:"projects/p/secrets/db/versions/latest"`
+		Short   string `gcpsecret:"db"`
+		Version string `gcpsecret:"db/versions/1"`
+		Other   string `gcpsecret:"projects/q/secrets/api"`
+	}
+	if err := l.Load(&settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Full != "s3cret" || settings.Short != "s3cret" || settings.Version != "old" || settings.Other != "key" {
+		t.Fatalf("unexpected values: %+v", settings)
+	}
+
+	testCases := []struct {
+		desc     string
+		settings interface{}
+		err      string
+	}{
+		{
+			desc: "missing secret",
+			settings: &struct {
+				Missing string `gcpsecret:"missing"`
+			}{},
+			err: "config: missing value for field 'Missing'",
+		},
+		{
+			desc: "client error",
+			settings: &struct {
+				Broken string `gcpsecret:"broken"`
+			}{},
+			err: "config: error loading field Broken for tag gcpsecret: permission denied",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := l.Load(tC.settings)
+			if err == nil || err.Error() != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%v'", tC.err, err)
+			}
+		})
+	}
+}
+
+func TestSourceWithoutClient(t *testing.T) {
+	var settings struct {
+		Secret string `gcpsecret:"projects/p/secrets/db"`
+	}
+	err := config.NewLoader(NewSource(nil)).Load(&settings)
+	expected := "config: error loading field Secret for tag gcpsecret: gcpsecret: client is not set"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error to be '%s' but was '%v'", expected, err)
+	}
+}
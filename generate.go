@@ -0,0 +1,164 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEnvExample writes a commented .env file with the environment variables
+// in the specs. Required variables are left empty, the others are commented out
+// with their default value.
+func WriteEnvExample(w io.Writer, specs []FieldSpec) error {
+	first := true
+	for _, spec := range specs {
+		k, found := specKey(spec, EnvTag)
+		if !found {
+			continue
+		}
+		var lines []string
+		if !first {
+			lines = append(lines, "")
+		}
+		first = false
+		if spec.Description != "" {
+			lines = append(lines, "# "+spec.Description)
+		}
+		switch {
+		case spec.Required:
+			lines = append(lines, "# Required.", k.Key+"=")
+		case spec.Secret:
+			lines = append(lines, "# "+k.Key+"=")
+		default:
+			lines = append(lines, "# "+k.Key+"="+spec.Default)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SSMParameter describes a parameter to create in SSM.
+type SSMParameter struct {
+	Name string `json:"name"`
+	// Type is either String or SecureString, based on the secure flag.
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// SSMParameters returns the SSM parameters in the specs.
+func SSMParameters(specs []FieldSpec) []SSMParameter {
+	params := []SSMParameter{}
+	for _, spec := range specs {
+		k, found := specKey(spec, SSMTag)
+		if !found {
+			continue
+		}
+		p := SSMParameter{
+			Name:        k.Key,
+			Type:        "String",
+			Description: spec.Description,
+			Required:    spec.Required,
+		}
+		if k.Secure {
+			p.Type = "SecureString"
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// WriteSSMParametersJSON writes the SSM parameters in the specs as a JSON array.
+func WriteSSMParametersJSON(w io.Writer, specs []FieldSpec) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(SSMParameters(specs))
+}
+
+// WriteSSMParametersYAML writes the SSM parameters in the specs as a YAML list.
+func WriteSSMParametersYAML(w io.Writer, specs []FieldSpec) error {
+	params := SSMParameters(specs)
+	if len(params) == 0 {
+		_, err := fmt.Fprintln(w, "[]")
+		return err
+	}
+	for _, p := range params {
+		lines := []string{
+			"- name: " + yamlString(p.Name),
+			"  type: " + p.Type,
+		}
+		if p.Description != "" {
+			lines = append(lines, "  description: "+yamlString(p.Description))
+		}
+		lines = append(lines, fmt.Sprintf("  required: %v", p.Required))
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteKubernetesManifests writes a ConfigMap and a Secret, both called name, with
+// the environment variables in the specs. Secret fields go in the Secret, the others
+// in the ConfigMap, with their default value if they have one.
+func WriteKubernetesManifests(w io.Writer, specs []FieldSpec, name string) error {
+	var data, secretData []string
+	for _, spec := range specs {
+		k, found := specKey(spec, EnvTag)
+		if !found {
+			continue
+		}
+		line := "  " + yamlString(k.Key) + ": " + yamlString(spec.Default)
+		if spec.Description != "" {
+			line = "  # " + spec.Description + "\n" + line
+		}
+		if spec.Secret {
+			secretData = append(secretData, line)
+		} else {
+			data = append(data, line)
+		}
+	}
+	docs := []string{
+		kubernetesManifest("v1", "ConfigMap", name, "data", data),
+		kubernetesManifest("v1", "Secret", name, "stringData", secretData),
+	}
+	_, err := fmt.Fprint(w, strings.Join(docs, "---\n"))
+	return err
+}
+
+func kubernetesManifest(apiVersion, kind, name, dataKey string, data []string) string {
+	lines := []string{
+		"apiVersion: " + apiVersion,
+		"kind: " + kind,
+		"metadata:",
+		"  name: " + yamlString(name),
+	}
+	if kind == "Secret" {
+		lines = append(lines, "type: Opaque")
+	}
+	if len(data) == 0 {
+		lines = append(lines, dataKey+": {}")
+	} else {
+		lines = append(lines, dataKey+":")
+		lines = append(lines, data...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// yamlString quotes s as a JSON string, which is also a valid YAML string.
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func specKey(spec FieldSpec, tag string) (SourceKey, bool) {
+	for _, k := range spec.Keys {
+		if k.Tag == tag {
+			return k, true
+		}
+	}
+	return SourceKey{}, false
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testGenerateSpecs = []FieldSpec{
+	{
+		Path:        "Host",
+		Keys:        []SourceKey{{Tag: "env", Key: "HOST"}},
+		Required:    true,
+		Description: "Host name",
+	},
+	{
+		Path:    "Port",
+		Keys:    []SourceKey{{Tag: "env", Key: "PORT"}},
+		Default: "80",
+	},
+	{
+		Path: "Password",
+		Keys: []SourceKey{
+			{Tag: "ssm", Key: "/prod/password", Secure: true},
+			{Tag: "env", Key: "PASSWORD"},
+		},
+		Required:    true,
+		Secret:      true,
+		Description: "Database password",
+	},
+	{
+		Path:    "Token",
+		Keys:    []SourceKey{{Tag: "env", Key: "TOKEN"}},
+		Default: "",
+		Secret:  true,
+	},
+	{
+		Path:    "Region",
+		Keys:    []SourceKey{{Tag: "ssm", Key: "/prod/region"}},
+		Default: "eu-west-1",
+	},
+}
+
+func TestWriteEnvExample(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEnvExample(&buf, testGenerateSpecs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `# Host name
+# Required.
+HOST=
+
+# PORT=80
+
+# Database password
+# Required.
+PASSWORD=
+
+# TOKEN=
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestWriteSSMParametersJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSSMParametersJSON(&buf, testGenerateSpecs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `[
+  {
+    "name": "/prod/password",
+    "type": "SecureString",
+    "description": "Database password",
+    "required": true
+  },
+  {
+    "name": "/prod/region",
+    "type": "String",
+    "required": false
+  }
+]
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestWriteSSMParametersYAML(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteSSMParametersYAML(&buf, testGenerateSpecs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `- name: "/prod/password"
+  type: SecureString
+  description: "Database password"
+  required: true
+- name: "/prod/region"
+  type: String
+  required: false
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+
+	buf.Reset()
+	err = WriteSSMParametersYAML(&buf, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("expected output to be '[]' but was '%s'", buf.String())
+	}
+}
+
+func TestWriteKubernetesManifests(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteKubernetesManifests(&buf, testGenerateSpecs, "myapp")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "myapp"
+data:
+  # Host name
+  "HOST": ""
+  "PORT": "80"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "myapp"
+type: Opaque
+stringData:
+  # Database password
+  "PASSWORD": ""
+  "TOKEN": ""
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
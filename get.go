@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetOption customizes how Get resolves a key.
+type GetOption func(*getOptions)
+
+type getOptions struct {
+	def        string
+	hasDefault bool
+}
+
+// WithDefault sets the value to use when no source provides one.
+func WithDefault(v string) GetOption {
+	return func(o *getOptions) {
+		o.def = v
+		o.hasDefault = true
+	}
+}
+
+// Optional makes Get return the zero value when no source provides a value,
+// instead of failing. It's the same as WithDefault("").
+func Optional() GetOption {
+	return WithDefault("")
+}
+
+// Get resolves the value of key across all the sources of the loader, as if it was
+// a struct field tagged with key for every source, and parses it as T. The key can
+// have flags like a tag, e.g. "api_key,secure". Like fields without a default,
+// Get fails if no source provides a value, unless WithDefault or Optional are used.
+func Get[T any](l *Loader, key string, opts ...GetOption) (T, error) {
+	var o getOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var v T
+	fv := reflect.ValueOf(&v).Elem()
+	f := field{
+		StructField: reflect.StructField{
+			Name: key,
+			Type: fv.Type(),
+			Tag:  getTag(l, key, o),
+		},
+		path: key,
+	}
+	if _, err := getFieldSetter(fv, f); err != nil {
+		return v, err
+	}
+	val, err := l.snapshot().loadFieldValue(f)
+	if _, ok := err.(*missingValue); ok {
+		return v, fmt.Errorf("config: missing value for key '%s'", key)
+	}
+	if err != nil {
+		return v, err
+	}
+	err = setFieldValue(fv, f, val)
+	return v, err
+}
+
+// MustGet is like Get but panics if the value can't be resolved.
+func MustGet[T any](l *Loader, key string, opts ...GetOption) T {
+	v, err := Get[T](l, key, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// getTag builds the tag of a struct field loaded from key with every source.
+func getTag(l *Loader, key string, o getOptions) reflect.StructTag {
+	var tags []string
+	for _, s := range l.sources {
+		tags = append(tags, s.Tag()+":"+strconv.Quote(key))
+	}
+	if o.hasDefault {
+		tags = append(tags, "default:"+strconv.Quote(o.def))
+	}
+	return reflect.StructTag(strings.Join(tags, " "))
+}
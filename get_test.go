@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	l := NewLoader(
+		&testSource{tag: "test", values: map[string]string{"PORT": "80", "NAME": "", "TIMEOUT": "", "BAD": "x", "EMPTY": ""}},
+		&testSource{tag: "happy", values: map[string]string{"PORT": "8080", "NAME": "", "TIMEOUT": "5", "BAD": "", "EMPTY": ""}},
+	)
+
+	port, err := Get[int](l, "PORT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if port != 8080 {
+		t.Errorf("expected value to be %d but was %d", 8080, port)
+	}
+
+	timeout, err := Get[time.Duration](l, "TIMEOUT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout != 5 {
+		t.Errorf("expected value to be %d but was %d", 5, timeout)
+	}
+
+	name, err := Get[string](l, "NAME", WithDefault("default"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "default" {
+		t.Errorf("expected value to be '%s' but was '%s'", "default", name)
+	}
+
+	empty, err := Get[bool](l, "EMPTY", Optional())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty {
+		t.Errorf("expected value to be false")
+	}
+
+	_, err = Get[string](l, "NAME")
+	if err == nil || err.Error() != "config: missing value for key 'NAME'" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = Get[int](l, "BAD")
+	if err == nil || err.Error() != `strconv.ParseInt: parsing "x": invalid syntax` {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = Get[string](l, "MISSING")
+	if err == nil || err.Error() != "config: error loading field MISSING for tag test: error getting key MISSING" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = Get[error](l, "PORT")
+	if err == nil || err.Error() != "config: field type error is not supported" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+}
+
+func TestMustGet(t *testing.T) {
+	l := NewLoader(&testSource{values: map[string]string{"PORT": "80", "NAME": ""}})
+	if port := MustGet[uint16](l, "PORT"); port != 80 {
+		t.Errorf("expected value to be %d but was %d", 80, port)
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected MustGet to panic")
+		}
+		if err, ok := r.(error); !ok || err.Error() != "config: missing value for key 'NAME'" {
+			t.Errorf("unexpected panic: %v", r)
+		}
+	}()
+	MustGet[string](l, "NAME")
+}
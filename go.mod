@@ -1,8 +1,10 @@
 module github.com/andreaperizzato/go-config
 
-go 1.12
+go 1.18
 
 require (
 	github.com/aws/aws-sdk-go v1.31.8
-	golang.org/x/net v0.0.0-20200520182314-0ba52f642ac2 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/jmespath/go-jmespath v0.3.0 // indirect
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPTag is the name of the tag to load values from a JSON document served over HTTP.
+// The key is the dotted path of the value in the document: http:"db.host".
+const HTTPTag = "http"
+
+// HTTPSourceConfig is the configuration for the creation of an HTTP Source.
+type HTTPSourceConfig struct {
+	// URL of the JSON document.
+	URL string
+	// Headers are added to every request.
+	Headers http.Header
+	// BearerToken is sent in the Authorization header if not empty.
+	BearerToken string
+	// Timeout of the requests. Defaults to 10 seconds.
+	Timeout time.Duration
+	// TLSConfig configures the TLS connections, e.g. with a custom CA or client certificates.
+	TLSConfig *tls.Config
+	// Client sends the requests, ignoring Timeout and TLSConfig if set.
+	Client *http.Client
+}
+
+// NewHTTPSource creates a Source for values in the JSON document at url. The document is
+// requested once per load, and only downloaded again if its ETag changed.
+func NewHTTPSource(url string) Source {
+	return NewHTTPSourceWithConfig(HTTPSourceConfig{URL: url})
+}
+
+// NewHTTPSourceWithConfig creates a Source for values in a JSON document served over HTTP specifying custom configuration.
+func NewHTTPSourceWithConfig(cfg HTTPSourceConfig) Source {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg.TLSConfig
+		cfg.Client = &http.Client{Timeout: cfg.Timeout, Transport: transport}
+	}
+	return &httpSource{cfg: cfg}
+}
+
+type httpSource struct {
+	cfg HTTPSourceConfig
+
+	mu   sync.Mutex
+	doc  Source
+	etag string
+}
+
+var _httpSourceIfaceCheck LookupSource = &httpSource{}
+var _httpSourceSnapshotIfaceCheck SnapshotSource = &httpSource{}
+
+func (s *httpSource) Tag() string {
+	return HTTPTag
+}
+
+func (s *httpSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *httpSource) Lookup(tag TagValue) (string, bool, error) {
+	snap, err := s.Snapshot()
+	if err != nil {
+		return "", false, err
+	}
+	return lookupValue(snap, tag)
+}
+
+// Snapshot requests the document and returns a Source for its values.
+func (s *httpSource) Snapshot() (Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s.doc, nil
+}
+
+// fetch downloads the document, unless it didn't change since the last request.
+func (s *httpSource) fetch() error {
+	req, err := http.NewRequest(http.MethodGet, s.cfg.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, values := range s.cfg.Headers {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if s.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.BearerToken)
+	}
+	if s.doc != nil && s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+	res, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && s.doc != nil {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("config: %s returned %d: %s", s.cfg.URL, res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	doc, err := NewDocumentSource(HTTPTag, res.Body)
+	if err != nil {
+		return fmt.Errorf("config: invalid document at %s: %v", s.cfg.URL, err)
+	}
+	s.doc = doc
+	s.etag = res.Header.Get("ETag")
+	return nil
+}
+
+// NewDocumentSource creates a Source for tag that reads the values of the JSON document
+// in r by dotted path, e.g. db.hosts.0. Strings are returned as they are, other values
+// as JSON, and null values are not found. It fails if r is not a valid JSON document.
+func NewDocumentSource(tag string, r io.Reader) (LookupSource, error) {
+	doc, err := decodeDocument(r)
+	if err != nil {
+		return nil, err
+	}
+	return &documentSource{tag: tag, doc: doc}, nil
+}
+
+// documentSource reads the values of a decoded document by dotted path.
+type documentSource struct {
+	tag string
+	doc interface{}
+}
+
+var _documentSourceIfaceCheck LookupSource = &documentSource{}
+
+func (s *documentSource) Tag() string {
+	return s.tag
+}
+
+func (s *documentSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *documentSource) Lookup(tag TagValue) (string, bool, error) {
+	return documentValue(s.doc, tag.Name)
+}
+
+// decodeDocument decodes a JSON document, keeping numbers as they are written.
+func decodeDocument(r io.Reader) (interface{}, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("document is null")
+	}
+	return doc, nil
+}
+
+// documentValue returns the value at the dotted path in a decoded document, e.g. db.hosts.0.
+// Strings are returned as they are, other values as JSON. Null values are not found.
+func documentValue(doc interface{}, path string) (string, bool, error) {
+	v := doc
+	for _, name := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			v = node[name]
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", false, nil
+			}
+			v = node[i]
+		default:
+			return "", false, nil
+		}
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case json.Number:
+		return v.String(), true, nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true, nil
+}
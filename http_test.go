@@ -0,0 +1,230 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testHTTPDocument struct {
+	mu       sync.Mutex
+	body     string
+	etag     string
+	requests int
+	fetches  int
+}
+
+func (d *testHTTPDocument) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Env") != "prod" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("unauthorized\n"))
+		return
+	}
+	if d.etag != "" && r.Header.Get("If-None-Match") == d.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	d.fetches++
+	w.Header().Set("ETag", d.etag)
+	w.Write([]byte(d.body))
+}
+
+func (d *testHTTPDocument) set(body, etag string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.body, d.etag = body, etag
+}
+
+func TestHTTPSource(t *testing.T) {
+	doc := &testHTTPDocument{
+		body: `{"db":{"host":"localhost","port":5432,"tls":true,"hosts":["a","b"],"password":null},"url":"https://a?b&c"}`,
+		etag: `"v1"`,
+	}
+	server := httptest.NewServer(doc)
+	defer server.Close()
+
+	s := NewHTTPSourceWithConfig(HTTPSourceConfig{
+		URL:         server.URL,
+		Headers:     http.Header{"X-Env": []string{"prod"}},
+		BearerToken: "token",
+	})
+	if s.Tag() != "http" {
+		t.Fatalf("expected tag to be '%s' but was '%s'", "http", s.Tag())
+	}
+	snap, err := s.(SnapshotSource).Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		out   string
+		found bool
+	}{
+		{name: "db.host", out: "localhost", found: true},
+		{name: "db.port", out: "5432", found: true},
+		{name: "db.tls", out: "true", found: true},
+		{name: "db.hosts", out: `["a","b"]`, found: true},
+		{name: "db.hosts.1", out: "b", found: true},
+		{name: "url", out: "https://a?b&c", found: true},
+		{name: "db.hosts.2"},
+		{name: "db.password"},
+		{name: "db.missing"},
+		{name: "db.host.missing"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			out, found, err := lookupValue(snap, newTagValue(tC.name, "Field"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out != tC.out || found != tC.found {
+				t.Fatalf("expected '%s' (found: %v) but was '%s' (found: %v)", tC.out, tC.found, out, found)
+			}
+		})
+	}
+	if doc.requests != 1 {
+		t.Fatalf("expected 1 request but was %d", doc.requests)
+	}
+}
+
+func TestHTTPSourceETag(t *testing.T) {
+	doc := &testHTTPDocument{body: `{"host":"localhost","port":"5432"}`, etag: `"v1"`}
+	server := httptest.NewServer(doc)
+	defer server.Close()
+
+	l := NewLoader(NewHTTPSourceWithConfig(HTTPSourceConfig{
+		URL:         server.URL,
+		Headers:     http.Header{"X-Env": []string{"prod"}},
+		BearerToken: "token",
+	}))
+	load := func(expected string, requests, fetches int) {
+		t.Helper()
+		var v struct {
+			Host string `http:"host"`
+			Port int    `http:"port"`
+		}
+		if err := l.Load(&v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v.Host != expected {
+			t.Fatalf("expected '%s' but was '%s'", expected, v.Host)
+		}
+		if doc.requests != requests || doc.fetches != fetches {
+			t.Fatalf("expected %d requests and %d fetches but were %d and %d", requests, fetches, doc.requests, doc.fetches)
+		}
+	}
+	// A single request for all the fields of a load.
+	load("localhost", 1, 1)
+	// The document is not downloaded again if its ETag didn't change.
+	load("localhost", 2, 1)
+	doc.set(`{"host":"remote","port":"5432"}`, `"v2"`)
+	load("remote", 3, 2)
+}
+
+func TestHTTPSourceTLS(t *testing.T) {
+	server := httptest.NewTLSServer(&testHTTPDocument{body: `{"host":"localhost"}`})
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	s := NewHTTPSourceWithConfig(HTTPSourceConfig{
+		URL:         server.URL,
+		Headers:     http.Header{"X-Env": []string{"prod"}},
+		BearerToken: "token",
+		TLSConfig:   &tls.Config{RootCAs: pool},
+	})
+	out, err := s.Get(newTagValue("host", "Field"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "localhost" {
+		t.Fatalf("expected '%s' but was '%s'", "localhost", out)
+	}
+}
+
+func TestHTTPSourceErrors(t *testing.T) {
+	doc := &testHTTPDocument{body: `{"host":`}
+	server := httptest.NewServer(doc)
+	defer server.Close()
+
+	testCases := []struct {
+		desc string
+		cfg  HTTPSourceConfig
+		err  string
+	}{
+		{
+			desc: "unauthorized",
+			cfg:  HTTPSourceConfig{URL: server.URL},
+			err:  "config: " + server.URL + " returned 401: unauthorized",
+		},
+		{
+			desc: "invalid document",
+			cfg: HTTPSourceConfig{
+				URL:         server.URL,
+				Headers:     http.Header{"X-Env": []string{"prod"}},
+				BearerToken: "token",
+			},
+			err: "config: invalid document at " + server.URL + ": unexpected EOF",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := NewHTTPSourceWithConfig(tC.cfg).Get(newTagValue("host", "Field"))
+			if err == nil || err.Error() != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%v'", tC.err, err)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceOverride(t *testing.T) {
+	doc := &testHTTPDocument{body: `{"host":"localhost","port":"5432"}`}
+	server := httptest.NewServer(doc)
+	defer server.Close()
+
+	s := NewHTTPSourceWithConfig(HTTPSourceConfig{
+		URL:         server.URL,
+		Headers:     http.Header{"X-Env": []string{"prod"}},
+		BearerToken: "token",
+	})
+	l := Override(NewLoader(s), "http", map[string]string{"host": "test"})
+	var v struct {
+		Host string `http:"host"`
+		Port int    `http:"port"`
+		User string `http:"user" default:"admin"`
+	}
+	if err := l.Load(&v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Host != "test" || v.Port != 5432 || v.User != "admin" {
+		t.Fatalf("unexpected values: %+v", v)
+	}
+	if doc.requests != 1 {
+		t.Fatalf("expected 1 request but was %d", doc.requests)
+	}
+}
+
+func TestNewDocumentSource(t *testing.T) {
+	s, err := NewDocumentSource("doc", strings.NewReader(`{"db":{"port":5432}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Tag() != "doc" {
+		t.Fatalf("expected tag to be '%s' but was '%s'", "doc", s.Tag())
+	}
+	out, found, err := lookupValue(s, newTagValue("db.port", "Field"))
+	if err != nil || !found || out != "5432" {
+		t.Fatalf("expected '5432' but was '%s' (found: %v, error: %v)", out, found, err)
+	}
+	if _, err := NewDocumentSource("doc", strings.NewReader(`null`)); err == nil || err.Error() != "document is null" {
+		t.Fatalf("expected error to be 'document is null' but was '%v'", err)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// KeyMapper transforms the tag of a field before it's passed to a source.
+type KeyMapper func(tag TagValue) TagValue
+
+// WithKeyMapper creates a Source that reads the values from src, transforming the tags with m.
+// Together with the functions that combine sources, it allows a single logical name to be read
+// from sources that expect different keys:
+//
+//	Layered("cfg", WithKeyMapper(NewSSMSource(), JoinPath("/app")), WithKeyMapper(NewEnvSource(), UpperSnakeKey))
+//
+// reads cfg:"db.host" from /app/db/host in SSM and from DB_HOST in the environment.
+//
+// The source is never a WatchableSource, even if src is: src would notify the changes by the
+// transformed keys, which can't be mapped back to the names in the tags. Use an Interval in the
+// WatcherConfig to reload the values of a mapped source.
+func WithKeyMapper(src Source, m KeyMapper) Source {
+	return &mappedSource{src: src, m: m}
+}
+
+type mappedSource struct {
+	src Source
+	m   KeyMapper
+}
+
+var _mappedSourceIfaceCheck LookupSource = &mappedSource{}
+var _mappedSourceKeyIfaceCheck KeySource = &mappedSource{}
+var _mappedSourceSnapshotIfaceCheck SnapshotSource = &mappedSource{}
+
+func (s *mappedSource) Tag() string {
+	return s.src.Tag()
+}
+
+func (s *mappedSource) Key(tag TagValue) string {
+	return sourceKey(s.src, s.m(tag))
+}
+
+func (s *mappedSource) Get(tag TagValue) (string, error) {
+	return s.src.Get(s.m(tag))
+}
+
+func (s *mappedSource) Lookup(tag TagValue) (string, bool, error) {
+	return lookupValue(s.src, s.m(tag))
+}
+
+func (s *mappedSource) Snapshot() (Source, error) {
+	return &mappedSource{src: lazySnapshot(s.src), m: s.m}, nil
+}
+
+// MapName creates a KeyMapper that transforms the name in the tag with f, keeping the flags.
+func MapName(f func(name string) string) KeyMapper {
+	return func(tag TagValue) TagValue {
+		tag.Name = f(tag.Name)
+		return tag
+	}
+}
+
+// UpperSnakeKey converts the name in the tag to upper snake case: db.maxConns becomes DB_MAX_CONNS.
+func UpperSnakeKey(tag TagValue) TagValue {
+	return MapName(UpperSnakeCase)(tag)
+}
+
+// LowerKebabKey converts the name in the tag to lower kebab case: db.maxConns becomes db-max-conns.
+func LowerKebabKey(tag TagValue) TagValue {
+	return MapName(LowerKebabCase)(tag)
+}
+
+// JoinPath creates a KeyMapper that turns the dots in the name into slashes and joins it
+// to prefix: with JoinPath("/app"), db.host becomes /app/db/host.
+func JoinPath(prefix string) KeyMapper {
+	return MapName(func(name string) string {
+		return path.Join(prefix, strings.Replace(name, ".", "/", -1))
+	})
+}
+
+// StripPrefix creates a KeyMapper that removes prefix from the name, if present:
+// with StripPrefix("app."), app.db.host becomes db.host.
+func StripPrefix(prefix string) KeyMapper {
+	return MapName(func(name string) string {
+		return strings.TrimPrefix(name, prefix)
+	})
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyMappers(t *testing.T) {
+	testCases := []struct {
+		desc string
+		m    KeyMapper
+		in   string
+		out  string
+	}{
+		{desc: "upper snake", m: UpperSnakeKey, in: "db.maxConns", out: "DB_MAX_CONNS"},
+		{desc: "upper snake acronym", m: UpperSnakeKey, in: "JWTSecret", out: "JWT_SECRET"},
+		{desc: "lower kebab", m: LowerKebabKey, in: "db.maxConns", out: "db-max-conns"},
+		{desc: "join path", m: JoinPath("/app"), in: "db.host", out: "/app/db/host"},
+		{desc: "join path with trailing slash", m: JoinPath("/app/"), in: "host", out: "/app/host"},
+		{desc: "strip prefix", m: StripPrefix("app."), in: "app.db.host", out: "db.host"},
+		{desc: "strip missing prefix", m: StripPrefix("app."), in: "db.host", out: "db.host"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			out := tC.m(newTagValue(tC.in+",secure", "Field"))
+			if out.Name != tC.out {
+				t.Fatalf("expected '%s' but was '%s'", tC.out, out.Name)
+			}
+			if !out.HasFlag("secure") {
+				t.Fatalf("expected the flags to be kept")
+			}
+		})
+	}
+}
+
+func TestWithKeyMapper(t *testing.T) {
+	env := NewEnvSourceWithConfig(EnvSourceConfig{
+		Prefix: "APP_",
+		Lookup: EnvironLookup([]string{"APP_DB_HOST=localhost", "APP_DB_MAX_CONNS="}),
+	})
+	ssm := &testLookupSource{testSource{tag: "ssm", values: map[string]string{
+		"/app/db/host":     "remote",
+		"/app/db/password": "s3cret",
+	}}}
+	l := NewLoader(Layered("cfg",
+		WithKeyMapper(ssm, JoinPath("/app")),
+		WithKeyMapper(env, UpperSnakeKey),
+	))
+
+	type settings struct {
+		Host     string `cfg:"db.host"`
+		Password string `cfg:"db.password"`
+		MaxConns string `cfg:"db.maxConns,allowempty" default:"10"`
+	}
+	var v settings
+	if err := l.Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{Host: "localhost", Password: "s3cret"}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+
+	if k := sourceKey(WithKeyMapper(env, UpperSnakeKey), newTagValue("db.host", "Host")); k != "APP_DB_HOST" {
+		t.Errorf("expected key to be '%s' but was '%s'", "APP_DB_HOST", k)
+	}
+	src := WithKeyMapper(ssm, JoinPath("/app"))
+	if src.Tag() != "ssm" {
+		t.Errorf("expected tag to be '%s' but was '%s'", "ssm", src.Tag())
+	}
+	out, err := src.Get(newTagValue("db.password", "Password"))
+	if err != nil || out != "s3cret" {
+		t.Errorf("expected '%s' but was '%s' (%v)", "s3cret", out, err)
+	}
+	if _, ok := WithKeyMapper(&testWatchableSource{}, UpperSnakeKey).(WatchableSource); ok {
+		t.Errorf("expected the mapped source not to be watchable")
+	}
+}
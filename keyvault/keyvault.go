@@ -0,0 +1,65 @@
+// Package keyvault provides a source for values stored in Azure Key Vault.
+package keyvault
+
+import (
+	"errors"
+	"strings"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+// Tag is the name of the tag to load values from Key Vault. The key is the name
+// of the secret, optionally followed by its version: keyvault:"db-password/3a5c...".
+// The latest version is loaded when the version is not set.
+const Tag = "keyvault"
+
+// ErrNotFound is returned by a Client when the secret doesn't exist,
+// which is treated as an unset value.
+var ErrNotFound = errors.New("keyvault: secret not found")
+
+// Client gets secrets from a vault. It can be implemented on top of
+// github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets, returning
+// ErrNotFound when the request fails with a 404 status code.
+type Client interface {
+	// GetSecret returns the value of a version of the secret, or of its latest
+	// version if version is empty.
+	GetSecret(name, version string) (string, error)
+}
+
+// NewSource creates a Source for values stored in Key Vault.
+func NewSource(client Client) config.Source {
+	return &source{client: client}
+}
+
+type source struct {
+	client Client
+}
+
+var _sourceIfaceCheck config.LookupSource = &source{}
+
+func (s *source) Tag() string {
+	return Tag
+}
+
+func (s *source) Get(tag config.TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *source) Lookup(tag config.TagValue) (string, bool, error) {
+	if s.client == nil {
+		return "", false, errors.New("keyvault: client is not set")
+	}
+	name, version := tag.Name, ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		name, version = name[:i], name[i+1:]
+	}
+	v, err := s.client.GetSecret(name, version)
+	if errors.Is(err, ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
@@ -0,0 +1,86 @@
+package keyvault
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	config "github.com/andreaperizzato/go-config"
+)
+
+type fakeClient map[string]string
+
+func (c fakeClient) GetSecret(name, version string) (string, error) {
+	if name == "broken" {
+		return "", fmt.Errorf("forbidden: %w", errors.New("access denied"))
+	}
+	if version == "" {
+		version = "latest"
+	}
+	v, found := c[name+"/"+version]
+	if !found {
+		return "", fmt.Errorf("GET %s: %w", name, ErrNotFound)
+	}
+	return v, nil
+}
+
+func TestSource(t *testing.T) {
+	client := fakeClient{
+		"db-password/latest": "s3cret",
+		"db-password/v1":     "old",
+		"empty/latest":       "",
+	}
+	l := config.NewLoader(NewSource(client))
+
+	var settings struct {
+		Password    string `keyvault:"db-password"`
+		OldPassword string `keyvault:"db-password/v1"`
+		Empty       string `keyvault:"empty,allowempty" default:"default"`
+	}
+	if err := l.Load(&settings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Password != "s3cret" || settings.OldPassword != "old" || settings.Empty != "" {
+		t.Fatalf("unexpected values: %+v", settings)
+	}
+
+	testCases := []struct {
+		desc     string
+		settings interface{}
+		err      string
+	}{
+		{
+			desc: "missing secret",
+			settings: &struct {
+				Missing string `keyvault:"missing"`
+			}{},
+			err: "config: missing value for field 'Missing'",
+		},
+		{
+			desc: "client error",
+			settings: &struct {
+				Broken string `keyvault:"broken"`
+			}{},
+			err: "config: error loading field Broken for tag keyvault: forbidden: access denied",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := l.Load(tC.settings)
+			if err == nil || err.Error() != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%v'", tC.err, err)
+			}
+		})
+	}
+}
+
+func TestSourceWithoutClient(t *testing.T) {
+	var settings struct {
+		Secret string `keyvault:"db-password"`
+	}
+	err := config.NewLoader(NewSource(nil)).Load(&settings)
+	expected := "config: error loading field Secret for tag keyvault: keyvault: client is not set"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error to be '%s' but was '%v'", expected, err)
+	}
+}
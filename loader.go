@@ -5,57 +5,268 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 )
 
+// FromTag is the name of the tag restricting the sources that can provide the value
+// of a field: from:"ssm,vault". The default value of the field is not used either.
+const FromTag = "from"
+
 // Loader loads values using multiple sources.
 type Loader struct {
 	sources []Source
+	naming  map[string]NameFunc
+	auto    bool
 }
 
 // NewLoader creates a new Loader that uses
 // all the sources.
 func NewLoader(scs ...Source) *Loader {
+	return NewLoaderWithConfig(LoaderConfig{
+		Sources: scs,
+	})
+}
+
+// LoaderConfig is the configuration for the creation of a Loader.
+type LoaderConfig struct {
+	Sources []Source
+	// Naming derives the key of a field for the source with the given tag
+	// when the tag doesn't specify one, e.g. env:"". The field name is used
+	// for sources without a NameFunc.
+	Naming map[string]NameFunc
+	// Auto loads fields that are not tagged for a source as if they had an empty tag.
+	// Tag a field with "-" to exclude it from a source, e.g. ssm:"-".
+	Auto bool
+}
+
+// NewLoaderWithConfig creates a new Loader specifying custom configuration.
+func NewLoaderWithConfig(cfg LoaderConfig) *Loader {
 	return &Loader{
-		sources: scs,
+		sources: cfg.Sources,
+		naming:  cfg.Naming,
+		auto:    cfg.Auto,
 	}
 }
 
-// Load reads the.
+// Load reads the values for the fields of v, which must be a pointer to a struct.
+// Fields of nested structs are loaded as well, and structs implementing Validator
+// are validated once all their fields are set.
 func (c *Loader) Load(v interface{}) error {
 	rv := reflect.ValueOf(v)
 	rv, err := getWritableValue(rv)
 	if err != nil {
 		return err
 	}
-	rt := reflect.TypeOf(v).Elem()
+	return c.snapshot().loadStruct(rv, "", loadOptions{})
+}
+
+// LoadWithProvenance is like Load, but it also returns where the value of each field
+// was loaded from. When loading fails, the provenance of the fields loaded so far is returned.
+func (c *Loader) LoadWithProvenance(v interface{}) (Provenance, error) {
+	rv := reflect.ValueOf(v)
+	rv, err := getWritableValue(rv)
+	if err != nil {
+		return nil, err
+	}
+	p := make(Provenance)
+	err = c.snapshot().loadStruct(rv, "", loadOptions{provenance: p})
+	return p, err
+}
+
+// snapshot returns a copy of c for a single load, where each SnapshotSource is
+// replaced by the snapshot taken the first time a field needs it.
+func (c *Loader) snapshot() *Loader {
+	sources := make([]Source, len(c.sources))
+	for i, s := range c.sources {
+		sources[i] = lazySnapshot(s)
+	}
+	return &Loader{
+		sources: sources,
+		naming:  c.naming,
+		auto:    c.auto,
+	}
+}
+
+// lazySnapshot returns a Source that reads the values of s from a snapshot taken
+// the first time it's needed, if s is a SnapshotSource, or s itself.
+func lazySnapshot(s Source) Source {
+	if o, ok := asOverrideSource(s); ok {
+		return o.snapshot()
+	}
+	if ss, ok := s.(SnapshotSource); ok {
+		return &loadSnapshot{src: ss}
+	}
+	return s
+}
+
+// loadSnapshot reads the values of a SnapshotSource from a single snapshot.
+type loadSnapshot struct {
+	src  SnapshotSource
+	once sync.Once
+	snap Source
+	err  error
+}
+
+var _loadSnapshotIfaceCheck LookupSource = &loadSnapshot{}
+var _loadSnapshotKeyIfaceCheck KeySource = &loadSnapshot{}
+
+func (s *loadSnapshot) Tag() string {
+	return s.src.Tag()
+}
+
+func (s *loadSnapshot) Key(tag TagValue) string {
+	return sourceKey(s.src, tag)
+}
+
+func (s *loadSnapshot) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *loadSnapshot) Lookup(tag TagValue) (string, bool, error) {
+	s.once.Do(func() {
+		s.snap, s.err = s.src.Snapshot()
+	})
+	if s.err != nil {
+		return "", false, s.err
+	}
+	return lookupValue(s.snap, tag)
+}
+
+// Provenance describes where the values of the fields were loaded from, by field path.
+type Provenance map[string]FieldProvenance
+
+// FieldProvenance describes where the value of a field was loaded from.
+type FieldProvenance struct {
+	// Tag is the tag of the source that provided the value,
+	// empty when no source provided one.
+	Tag string `json:"tag,omitempty"`
+	// Key is the key used to load the value from the source.
+	Key string `json:"key,omitempty"`
+	// Default is true when the value of the default tag was used.
+	Default bool `json:"default,omitempty"`
+	// Overridden are the tags of the sources that provided a value
+	// that was replaced by the value of a later source, in order.
+	Overridden []string `json:"overridden,omitempty"`
+}
+
+// Validator is implemented by structs that validate their values once loaded,
+// e.g. to check rules that span multiple fields.
+type Validator interface {
+	Validate() error
+}
+
+// field is a struct field with its path from the loaded struct, e.g. DB.Host.
+type field struct {
+	reflect.StructField
+	path string
+}
+
+// loadOptions customizes how loadStruct loads the fields.
+type loadOptions struct {
+	// provenance records where the values are loaded from, if not nil.
+	provenance Provenance
+	// only restricts loading to the fields it returns true for, if not nil.
+	// The other fields keep their values.
+	only func(f field) bool
+	// initial, if valid, is the struct the fields are reset to before loading them,
+	// so that fields no source provides any more don't keep their previous values.
+	initial reflect.Value
+}
+
+func (c *Loader) loadStruct(rv reflect.Value, path string, opts loadOptions) error {
+	rt := rv.Type()
 	for i := 0; i < rv.NumField(); i++ {
-		ft := rt.Field(i)
+		f := field{StructField: rt.Field(i), path: rt.Field(i).Name}
+		if path != "" {
+			f.path = path + "." + f.path
+		}
 		fv := rv.Field(i)
-		set, err := getFieldSetter(fv, ft)
+		if fv.Kind() == reflect.Struct && fv.CanSet() && !c.hasSourceTag(f) {
+			nested := opts
+			if opts.initial.IsValid() {
+				nested.initial = opts.initial.Field(i)
+			}
+			err := c.loadStruct(fv, f.path, nested)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if opts.only != nil && !opts.only(f) {
+			continue
+		}
+		if opts.initial.IsValid() && fv.CanSet() {
+			fv.Set(opts.initial.Field(i))
+		}
+
+		_, err := getFieldSetter(fv, f)
 		if err != nil {
 			return err
 		}
 
-		val, err := loadFieldValue(ft, c.sources)
+		val, err := c.loadFieldValue(f)
+		if opts.provenance != nil && val.matched {
+			opts.provenance[f.path] = val.provenance
+		}
 		if err != nil {
 			return err
 		}
 
-		if val == "" {
-			continue
+		err = setFieldValue(fv, f, val)
+		if err != nil {
+			return err
 		}
+	}
+	return validateStruct(rv, path)
+}
 
-		err = set(fv, val)
+// setFieldValue sets the value loaded for a field and validates it.
+func setFieldValue(fv reflect.Value, f field, val fieldValue) error {
+	set, err := getFieldSetter(fv, f)
+	if err != nil {
+		return err
+	}
+	if val.value != "" {
+		err = set(fv, val.value)
 		if err != nil {
 			return err
 		}
+	} else if val.explicit {
+		fv.Set(reflect.Zero(fv.Type()))
+	}
+	return validateField(fv, f, val.explicit || val.provenance.Default)
+}
+
+// hasSourceTag returns true if the field is explicitly tagged for at least one source.
+func (c *Loader) hasSourceTag(f field) bool {
+	for _, s := range c.sources {
+		if _, found := f.Tag.Lookup(s.Tag()); found {
+			return true
+		}
 	}
-	return nil
+	return false
 }
 
-func getFieldSetter(fv reflect.Value, ft reflect.StructField) (fieldSetter, error) {
+func validateStruct(rv reflect.Value, path string) error {
+	v, ok := rv.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+	err := v.Validate()
+	if err == nil {
+		return nil
+	}
+	if path == "" {
+		return fmt.Errorf("config: invalid configuration: %w", err)
+	}
+	return fmt.Errorf("config: invalid configuration for '%s': %w", path, err)
+}
+
+func getFieldSetter(fv reflect.Value, f field) (fieldSetter, error) {
 	if !fv.CanSet() {
-		return nil, fmt.Errorf("config: field %s can't be set", ft.Name)
+		return nil, fmt.Errorf("config: field %s can't be set", f.path)
 	}
 	set, _ := setters[fv.Kind()]
 	if set == nil {
@@ -77,37 +288,64 @@ func getWritableValue(rv reflect.Value) (wv reflect.Value, err error) {
 	return
 }
 
-func loadFieldValue(ft reflect.StructField, sources []Source) (value string, err error) {
+// fieldValue is the value loaded for a field.
+type fieldValue struct {
+	value string
+	// explicit is true when a source provided the value, even if empty.
+	explicit bool
+	// matched is true when the field is tagged for at least one source.
+	matched    bool
+	provenance FieldProvenance
+}
+
+func (c *Loader) loadFieldValue(f field) (result fieldValue, err error) {
 	hasDeprecatedOptionalFlag := false
 	matchedTags := 0
-	for _, s := range sources {
-		tagValue, found := ft.Tag.Lookup(s.Tag())
+	allowed, err := allowedSources(f)
+	if err != nil {
+		result.matched = true
+		return result, err
+	}
+	// disallowed are the tags of the sources that provided a value not allowed by the from tag.
+	var disallowed []string
+	for _, s := range c.sources {
+		tag, found := c.fieldTag(f, s)
 		if !found {
 			continue
 		}
 		matchedTags++
-		tag := newTagValue(tagValue, ft.Name)
-		newValue, err := s.Get(tag)
+		result.matched = true
+		newValue, found, err := lookupValue(s, tag)
 		if err != nil {
-			return "", fmt.Errorf("config: error loading field %v for tag %s: %v", ft.Name, s.Tag(), err)
-		}
-		if newValue != "" {
-			value = newValue
+			return result, fmt.Errorf("config: error loading field %v for tag %s: %v", f.path, s.Tag(), err)
 		}
+		// Empty values are ignored unless the field opts in with the allowempty flag,
+		// so that an unset variable doesn't override the value from an earlier source.
 		hasDeprecatedOptionalFlag = tag.HasFlag("optional")
+		if newValue != "" || (found && (tag.HasFlag("allowempty") || isOverridden(s, tag))) {
+			if allowed != nil && indexOf(allowed, s.Tag()) < 0 {
+				disallowed = append(disallowed, s.Tag())
+				continue
+			}
+			if result.explicit {
+				result.provenance.Overridden = append(result.provenance.Overridden, result.provenance.Tag)
+			}
+			result.value = newValue
+			result.explicit = true
+			result.provenance.Tag = s.Tag()
+			result.provenance.Key = sourceKey(s, tag)
+		}
 	}
-	if matchedTags == 0 {
-		return
-	}
-
-	if value != "" {
+	if matchedTags == 0 || result.explicit {
 		return
 	}
 
-	hasDefault := false
-	if value == "" {
-		value, hasDefault = ft.Tag.Lookup("default")
+	value, hasDefault := f.Tag.Lookup("default")
+	if allowed != nil && (len(disallowed) > 0 || hasDefault) {
+		return result, &disallowedSource{fieldName: f.path, allowed: allowed, tags: disallowed}
 	}
+	result.value = value
+	result.provenance.Default = hasDefault
 
 	// Previous version of this package supported an optional flag: env:"VAR,optional"
 	// which would prevent the loader from failing when the field is not set.
@@ -115,17 +353,79 @@ func loadFieldValue(ft reflect.StructField, sources []Source) (value string, err
 	// The following condition explicitly checks for that case and handles it in order to be
 	// retro-compatible.
 	if value == "" && !hasDefault && matchedTags == 1 && hasDeprecatedOptionalFlag {
-		return "", nil
+		return
 	}
 
 	if value == "" && !hasDefault {
-		return "", missingValueError(ft.Name)
+		return result, missingValueError(f.path)
 	}
 	return
 }
 
+// fieldTag returns the tag of the field for the source, deriving the key from
+// the name of the field when the tag doesn't specify one.
+func (c *Loader) fieldTag(f field, s Source) (TagValue, bool) {
+	tagValue, found := f.Tag.Lookup(s.Tag())
+	if (!found && !c.auto) || tagValue == "-" {
+		return TagValue{}, false
+	}
+	name := f.Name
+	if nameFunc := c.naming[s.Tag()]; nameFunc != nil {
+		name = nameFunc(f.path)
+	}
+	return newTagValue(tagValue, name), true
+}
+
 func missingValueError(fieldName string) error {
-	return fmt.Errorf("config: missing value for field '%s'", fieldName)
+	return &missingValue{fieldName: fieldName}
+}
+
+type missingValue struct {
+	fieldName string
+}
+
+func (e *missingValue) Error() string {
+	return fmt.Sprintf("config: missing value for field '%s'", e.fieldName)
+}
+
+// isOverridden returns true if the value of the name in the tag is set with Override.
+func isOverridden(s Source, tag TagValue) bool {
+	o, ok := asOverrideSource(s)
+	return ok && o.overridden(tag)
+}
+
+// allowedSources returns the tags of the sources allowed by the from tag of the field,
+// nil if the field doesn't have one.
+func allowedSources(f field) ([]string, error) {
+	from, found := f.Tag.Lookup(FromTag)
+	if !found {
+		return nil, nil
+	}
+	var allowed []string
+	for _, tag := range strings.Split(from, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			allowed = append(allowed, tag)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("config: invalid from tag for field '%s': no sources", f.path)
+	}
+	return allowed, nil
+}
+
+// disallowedSource is the error of a field whose value is only provided by
+// sources that are not allowed by its from tag, or by its default tag.
+type disallowedSource struct {
+	fieldName string
+	allowed   []string
+	tags      []string
+}
+
+func (e *disallowedSource) Error() string {
+	if len(e.tags) == 0 {
+		return fmt.Sprintf("config: field '%s' must be loaded from %s but only has a default value", e.fieldName, strings.Join(e.allowed, ", "))
+	}
+	return fmt.Sprintf("config: field '%s' must be loaded from %s but was only set by %s", e.fieldName, strings.Join(e.allowed, ", "), strings.Join(e.tags, ", "))
 }
 
 type fieldSetter func(fv reflect.Value, val string) error
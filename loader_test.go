@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -450,3 +451,402 @@ func Test_DeprecatedOptionalFlag(t *testing.T) {
 		t.Errorf("expected output to be %v but was %v", out, v)
 	}
 }
+
+type testLookupSource struct {
+	testSource
+}
+
+func (ts *testLookupSource) Lookup(tag TagValue) (string, bool, error) {
+	v, found := ts.values[tag.Name]
+	return v, found, nil
+}
+
+func Test_ExplicitEmptyValues(t *testing.T) {
+	type settings struct {
+		A string `test:"a" happy:"a,allowempty"`
+		B string `test:"b" happy:"b" default:"default"`
+		C string `test:"c" happy:"c,allowempty" default:"default"`
+		D int    `happy:"d,allowempty"`
+		E string `test:"e,allowempty" happy:"e,allowempty" default:"default"`
+	}
+	s1 := &testSource{tag: "test", values: map[string]string{
+		"a": "from test",
+		"b": "from test",
+		"c": "",
+		"e": "",
+	}}
+	s2 := &testLookupSource{testSource{tag: "happy", values: map[string]string{
+		"a": "",
+		"b": "",
+		"d": "",
+	}}}
+	v := settings{D: 10}
+	out := settings{
+		A: "",
+		B: "from test",
+		C: "default",
+		D: 0,
+		E: "default",
+	}
+
+	err := NewLoader(s1, s2).Load(&v)
+	if err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+}
+
+func Test_Naming(t *testing.T) {
+	type settings struct {
+		MaxConnections int    `test:"" happy:""`
+		JWTSecret      string `test:"SECRET"`
+		LogLevel       string `default:"info"`
+		Ignored        string `test:"-" happy:"-"`
+	}
+	s1 := &testSource{tag: "test", values: map[string]string{
+		"MAX_CONNECTIONS": "10",
+		"SECRET":          "s3cret",
+		"LOG_LEVEL":       "",
+	}}
+	s2 := &testSource{tag: "happy", values: map[string]string{
+		"/max-connections": "",
+		"/jwt-secret":      "",
+		"/log-level":       "debug",
+	}}
+	naming := map[string]NameFunc{
+		"test":  UpperSnakeCase,
+		"happy": PrefixedName("/", LowerKebabCase),
+	}
+
+	t.Run("tagged fields", func(t *testing.T) {
+		l := NewLoaderWithConfig(LoaderConfig{
+			Sources: []Source{s1, s2},
+			Naming:  naming,
+		})
+		v := settings{}
+		out := settings{MaxConnections: 10, JWTSecret: "s3cret"}
+		err := l.Load(&v)
+		if err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+		if !reflect.DeepEqual(out, v) {
+			t.Errorf("expected output to be %v but was %v", out, v)
+		}
+	})
+
+	t.Run("auto", func(t *testing.T) {
+		l := NewLoaderWithConfig(LoaderConfig{
+			Sources: []Source{s1, s2},
+			Naming:  naming,
+			Auto:    true,
+		})
+		v := settings{}
+		out := settings{MaxConnections: 10, JWTSecret: "s3cret", LogLevel: "debug"}
+		err := l.Load(&v)
+		if err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+		if !reflect.DeepEqual(out, v) {
+			t.Errorf("expected output to be %v but was %v", out, v)
+		}
+	})
+
+	t.Run("nested fields are named after their path", func(t *testing.T) {
+		l := NewLoaderWithConfig(LoaderConfig{
+			Sources: []Source{&testSource{values: map[string]string{"DB_HOST": "localhost"}}},
+			Naming:  map[string]NameFunc{"test": UpperSnakeCase},
+			Auto:    true,
+		})
+		v := struct{ DB struct{ Host string } }{}
+		err := l.Load(&v)
+		if err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+		if v.DB.Host != "localhost" {
+			t.Errorf("expected value to be '%s' but was '%s'", "localhost", v.DB.Host)
+		}
+	})
+
+	t.Run("flags without a name", func(t *testing.T) {
+		l := NewLoaderWithConfig(LoaderConfig{
+			Sources: []Source{&testLookupSource{testSource{values: map[string]string{"LOG_LEVEL": ""}}}},
+			Naming:  map[string]NameFunc{"test": UpperSnakeCase},
+			Auto:    true,
+		})
+		v := struct {
+			LogLevel string `test:",allowempty" default:"info"`
+		}{}
+		err := l.Load(&v)
+		if err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+		if v.LogLevel != "" {
+			t.Errorf("expected the empty value but was '%s'", v.LogLevel)
+		}
+	})
+
+	t.Run("field name without a NameFunc", func(t *testing.T) {
+		l := NewLoaderWithConfig(LoaderConfig{
+			Sources: []Source{&testSource{values: map[string]string{"Value": "x"}}},
+			Auto:    true,
+		})
+		v := struct{ Value string }{}
+		err := l.Load(&v)
+		if err != nil {
+			t.Fatalf("expected error to be nil but was '%s'", err.Error())
+		}
+		if v.Value != "x" {
+			t.Errorf("expected value to be '%s' but was '%s'", "x", v.Value)
+		}
+	})
+}
+
+type testTLS struct {
+	Cert string `test:"cert" default:""`
+	Key  string `test:"key" default:""`
+}
+
+func (t testTLS) Validate() error {
+	if (t.Cert == "") != (t.Key == "") {
+		return errors.New("cert and key must be set together")
+	}
+	return nil
+}
+
+type testPool struct {
+	Min int `test:"min"`
+	Max int `test:"max"`
+}
+
+func (p *testPool) Validate() error {
+	if p.Min > p.Max {
+		return fmt.Errorf("min %d is greater than max %d", p.Min, p.Max)
+	}
+	return nil
+}
+
+type testServer struct {
+	TLS  testTLS
+	Pool testPool
+}
+
+func Test_NestedStructs(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		values map[string]string
+		out    testServer
+		err    string
+	}{
+		{
+			desc:   "loads nested fields",
+			values: map[string]string{"cert": "c", "key": "k", "min": "1", "max": "2"},
+			out: testServer{
+				TLS:  testTLS{Cert: "c", Key: "k"},
+				Pool: testPool{Min: 1, Max: 2},
+			},
+		},
+		{
+			desc:   "reports the path of missing fields",
+			values: map[string]string{"cert": "", "key": "", "min": "", "max": "2"},
+			err:    "config: missing value for field 'Pool.Min'",
+		},
+		{
+			desc:   "calls Validate with a value receiver",
+			values: map[string]string{"cert": "c", "key": "", "min": "1", "max": "2"},
+			err:    "config: invalid configuration for 'TLS': cert and key must be set together",
+		},
+		{
+			desc:   "calls Validate with a pointer receiver",
+			values: map[string]string{"cert": "", "key": "", "min": "3", "max": "2"},
+			err:    "config: invalid configuration for 'Pool': min 3 is greater than max 2",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var v testServer
+			err := NewLoader(&testSource{values: tC.values}).Load(&v)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if tC.err == "" && !reflect.DeepEqual(tC.out, v) {
+				t.Errorf("expected output to be %v but was %v", tC.out, v)
+			}
+		})
+	}
+}
+
+type testRootValidator struct {
+	Server testServer
+	Name   string `test:"name"`
+}
+
+var errTestInvalidName = errors.New("invalid name")
+
+func (r *testRootValidator) Validate() error {
+	if r.Name != r.Server.TLS.Cert {
+		return errTestInvalidName
+	}
+	return nil
+}
+
+func Test_RootValidator(t *testing.T) {
+	values := map[string]string{"cert": "c", "key": "k", "min": "1", "max": "2", "name": "n"}
+	var v testRootValidator
+	err := NewLoader(&testSource{values: values}).Load(&v)
+	if err == nil {
+		t.Fatal("expected to get an error, got nil")
+	}
+	if err.Error() != "config: invalid configuration: invalid name" {
+		t.Errorf("unexpected error: '%s'", err.Error())
+	}
+	if !errors.Is(err, errTestInvalidName) {
+		t.Errorf("expected error to wrap the error returned by Validate")
+	}
+}
+
+func Test_LoadWithProvenance(t *testing.T) {
+	v := struct {
+		A string `test:"a" happy:"b"`
+		B string `test:"b" happy:"b"`
+		C string `test:"c" default:"default"`
+		D string `test:"d" default:""`
+		E string `json:"e"`
+		N struct {
+			F string `happy:"f"`
+		}
+	}{}
+	s1 := &testSource{tag: "test", values: map[string]string{"a": "a", "b": "b", "c": "", "d": ""}}
+	s2 := &testSource{tag: "happy", values: map[string]string{"b": "happy b", "f": "f"}}
+	out := Provenance{
+		"A":   {Tag: "happy", Key: "b", Overridden: []string{"test"}},
+		"B":   {Tag: "happy", Key: "b", Overridden: []string{"test"}},
+		"C":   {Default: true},
+		"D":   {Default: true},
+		"N.F": {Tag: "happy", Key: "f"},
+	}
+
+	p, err := NewLoader(s1, s2).LoadWithProvenance(&v)
+	if err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if !reflect.DeepEqual(out, p) {
+		t.Errorf("expected provenance to be %v but was %v", out, p)
+	}
+}
+
+func Test_LoadWithProvenanceError(t *testing.T) {
+	v := struct {
+		A string `test:"a"`
+		B string `test:"b"`
+	}{}
+	s := &testSource{values: map[string]string{"a": "a", "b": ""}}
+	out := Provenance{
+		"A": {Tag: "test", Key: "a"},
+		"B": {},
+	}
+
+	p, err := NewLoader(s).LoadWithProvenance(&v)
+	if err == nil {
+		t.Fatal("expected to get an error, got nil")
+	}
+	if !reflect.DeepEqual(out, p) {
+		t.Errorf("expected provenance to be %v but was %v", out, p)
+	}
+}
+
+func Test_FromTag(t *testing.T) {
+	env := &testLookupSource{testSource{tag: "env", values: map[string]string{"PASSWORD": "env", "KEY": "env"}}}
+	ssm := &testLookupSource{testSource{tag: "ssm", values: map[string]string{"/password": "ssm", "/empty": ""}}}
+	vault := &testLookupSource{testSource{tag: "vault", values: map[string]string{}}}
+
+	testCases := []struct {
+		desc string
+		v    interface{}
+		out  interface{}
+		err  string
+	}{
+		{
+			desc: "value from an allowed source",
+			v: &struct {
+				Password string `ssm:"/password" env:"PASSWORD" from:"ssm, vault"`
+			}{},
+			out: &struct {
+				Password string `ssm:"/password" env:"PASSWORD" from:"ssm, vault"`
+			}{Password: "ssm"},
+		},
+		{
+			desc: "allowed source before a disallowed one",
+			v: &struct {
+				Password string `env:"PASSWORD" ssm:"/password" from:"ssm"`
+			}{},
+			out: &struct {
+				Password string `env:"PASSWORD" ssm:"/password" from:"ssm"`
+			}{Password: "ssm"},
+		},
+		{
+			desc: "empty value from an allowed source",
+			v: &struct {
+				Password string `ssm:"/empty,allowempty" env:"PASSWORD" from:"ssm"`
+			}{},
+			out: &struct {
+				Password string `ssm:"/empty,allowempty" env:"PASSWORD" from:"ssm"`
+			}{},
+		},
+		{
+			desc: "value only from a disallowed source",
+			v: &struct {
+				Key string `vault:"app#key" env:"KEY" from:"ssm,vault"`
+			}{},
+			err: "config: field 'Key' must be loaded from ssm, vault but was only set by env",
+		},
+		{
+			desc: "default value",
+			v: &struct {
+				Key string `vault:"app#key" default:"default" from:"vault"`
+			}{},
+			err: "config: field 'Key' must be loaded from vault but only has a default value",
+		},
+		{
+			desc: "missing value",
+			v: &struct {
+				Key string `vault:"app#key" from:"vault"`
+			}{},
+			err: "config: missing value for field 'Key'",
+		},
+		{
+			desc: "empty from tag",
+			v: &struct {
+				Key string `env:"KEY" from:""`
+			}{},
+			err: "config: invalid from tag for field 'Key': no sources",
+		},
+		{
+			desc: "from tag without sources",
+			v: &struct {
+				Key string `env:"KEY" from:" , "`
+			}{},
+			err: "config: invalid from tag for field 'Key': no sources",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := NewLoader(env, ssm, vault).Load(tC.v)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if tC.out != nil && !reflect.DeepEqual(tC.out, tC.v) {
+				t.Errorf("expected output to be %v but was %v", tC.out, tC.v)
+			}
+		})
+	}
+}
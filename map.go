@@ -0,0 +1,160 @@
+package config
+
+import "sync"
+
+// MapSource is a Source that reads the values from a map, by the name in the tag.
+// Values can be changed while loading, e.g. by tests of a Watcher.
+type MapSource struct {
+	tag    string
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+var _mapSourceIfaceCheck LookupSource = &MapSource{}
+
+// NewMapSource creates a Source for tag with a copy of values.
+func NewMapSource(tag string, values map[string]string) *MapSource {
+	s := &MapSource{tag: tag, values: make(map[string]string, len(values))}
+	for k, v := range values {
+		s.values[k] = v
+	}
+	return s
+}
+
+// Tag returns the tag of the source.
+func (s *MapSource) Tag() string {
+	return s.tag
+}
+
+// Get returns the value of the name in the tag, empty if not set.
+func (s *MapSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+// Lookup returns the value of the name in the tag and whether it's set.
+func (s *MapSource) Lookup(tag TagValue) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, found := s.values[tag.Name]
+	return v, found, nil
+}
+
+// Set sets the value of key.
+func (s *MapSource) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes the value of key.
+func (s *MapSource) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Override returns a copy of l where values override the ones of the source for tag,
+// by the name in the tag, e.g. Override(l, "ssm", map[string]string{"/app/db/password": "test"}).
+// Overridden values are used even if they're empty, as if the fields had the allowempty flag.
+// The values are added as a new source if l doesn't have a source for tag.
+func Override(l *Loader, tag string, values map[string]string) *Loader {
+	overrides := NewMapSource(tag, values)
+	sources := make([]Source, 0, len(l.sources)+1)
+	found := false
+	for _, s := range l.sources {
+		if s.Tag() == tag {
+			s = newOverrideSource(&overrideSource{src: s, overrides: overrides})
+			found = true
+		}
+		sources = append(sources, s)
+	}
+	if !found {
+		sources = append(sources, &overrideSource{overrides: overrides})
+	}
+	return &Loader{
+		sources: sources,
+		naming:  l.naming,
+		auto:    l.auto,
+	}
+}
+
+// newOverrideSource returns s, or a watchable version of s if its source is watchable.
+func newOverrideSource(s *overrideSource) Source {
+	if ws, ok := s.src.(WatchableSource); ok {
+		return &watchableOverrideSource{overrideSource: s, ws: ws}
+	}
+	return s
+}
+
+// overrideSource reads the values from overrides, and from src if not overridden.
+type overrideSource struct {
+	// src is nil if the loader didn't have a source for the tag.
+	src       Source
+	overrides *MapSource
+}
+
+var _overrideSourceIfaceCheck LookupSource = &overrideSource{}
+var _overrideSourceKeyIfaceCheck KeySource = &overrideSource{}
+
+func (s *overrideSource) Tag() string {
+	return s.overrides.Tag()
+}
+
+func (s *overrideSource) Key(tag TagValue) string {
+	if s.overridden(tag) || s.src == nil {
+		return tag.Name
+	}
+	return sourceKey(s.src, tag)
+}
+
+func (s *overrideSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *overrideSource) Lookup(tag TagValue) (string, bool, error) {
+	if v, found, _ := s.overrides.Lookup(tag); found || s.src == nil {
+		return v, found, nil
+	}
+	return lookupValue(s.src, tag)
+}
+
+// overridden returns true if the value of the name in the tag is overridden.
+func (s *overrideSource) overridden(tag TagValue) bool {
+	_, found, _ := s.overrides.Lookup(tag)
+	if o, ok := asOverrideSource(s.src); ok && !found {
+		return o.overridden(tag)
+	}
+	return found
+}
+
+// asOverrideSource returns the overrideSource behind s, if any.
+func asOverrideSource(s Source) (*overrideSource, bool) {
+	switch s := s.(type) {
+	case *overrideSource:
+		return s, true
+	case *watchableOverrideSource:
+		return s.overrideSource, true
+	}
+	return nil, false
+}
+
+// snapshot returns a copy of s where src is read from a single snapshot.
+func (s *overrideSource) snapshot() *overrideSource {
+	if s.src == nil {
+		return s
+	}
+	return &overrideSource{src: lazySnapshot(s.src), overrides: s.overrides}
+}
+
+type watchableOverrideSource struct {
+	*overrideSource
+	ws WatchableSource
+}
+
+var _watchableOverrideSourceIfaceCheck WatchableSource = &watchableOverrideSource{}
+
+func (s *watchableOverrideSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	return s.ws.Watch(stop, events)
+}
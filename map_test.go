@@ -0,0 +1,148 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMapSource(t *testing.T) {
+	values := map[string]string{"host": "localhost", "empty": ""}
+	s := NewMapSource("test", values)
+	// Changing the map doesn't change the source.
+	values["host"] = "remote"
+	if s.Tag() != "test" {
+		t.Fatalf("expected tag to be '%s' but was '%s'", "test", s.Tag())
+	}
+
+	type settings struct {
+		Host  string `test:"host"`
+		Empty string `test:"empty,allowempty" default:"default"`
+		Port  int    `test:"port" default:"80"`
+	}
+	var v settings
+	if err := NewLoader(s).Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{Host: "localhost", Port: 80}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+
+	s.Set("port", "8080")
+	s.Delete("empty")
+	if err := NewLoader(s).Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{Host: "localhost", Empty: "default", Port: 8080}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+}
+
+func TestMapSourceConcurrency(t *testing.T) {
+	s := NewMapSource("test", nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.Set("key", "value")
+			s.Delete("key")
+		}()
+		go func() {
+			defer wg.Done()
+			s.Get(newTagValue("key", "Key"))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOverride(t *testing.T) {
+	type settings struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD" ssm:"/app/password"`
+		Debug    string `env:"DEBUG,allowempty"`
+	}
+	env := NewEnvSourceWithConfig(EnvSourceConfig{
+		Prefix: "APP_",
+		Lookup: EnvironLookup([]string{"APP_HOST=localhost", "APP_PASSWORD=env", "APP_DEBUG=true"}),
+	})
+	l := NewLoader(env)
+	overridden := Override(Override(l, "env", map[string]string{"DEBUG": ""}), "ssm", map[string]string{
+		"/app/password": "s3cret",
+	})
+
+	var v settings
+	if err := overridden.Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{Host: "localhost", Password: "s3cret", Debug: ""}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+
+	// The original loader is not changed.
+	if err := l.Load(&v); err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{Host: "localhost", Password: "env", Debug: "true"}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+}
+
+func TestOverrideEmptyValues(t *testing.T) {
+	type settings struct {
+		A string `env:"A" default:"def"`
+		B string `env:"B" ssm:"B"`
+		C string `env:"C" default:"def"`
+	}
+	env := NewMapSource("env", map[string]string{"A": "real", "B": "real", "C": "real"})
+	l := Override(NewLoader(env), "env", map[string]string{"A": ""})
+	l = Override(l, "env", map[string]string{"C": "other"})
+	l = Override(l, "ssm", map[string]string{"B": ""})
+
+	var v settings
+	p, err := l.LoadWithProvenance(&v)
+	if err != nil {
+		t.Fatalf("expected error to be nil but was '%s'", err.Error())
+	}
+	if out := (settings{C: "other"}); !reflect.DeepEqual(out, v) {
+		t.Errorf("expected output to be %v but was %v", out, v)
+	}
+	if out := (FieldProvenance{Tag: "env", Key: "A"}); !reflect.DeepEqual(out, p["A"]) {
+		t.Errorf("expected provenance to be %+v but was %+v", out, p["A"])
+	}
+}
+
+func TestOverrideWatch(t *testing.T) {
+	s := &testWatchableSource{
+		testMutableSource: testMutableSource{values: map[string]string{"rate": "1"}},
+		events:            make(chan string),
+	}
+	changed := make(chan []Change)
+	var v testWatchedSettings
+	l := Override(NewLoader(s), "test", map[string]string{"max": "20"})
+	w, err := NewWatcher(l, &v, WatcherConfig{
+		OnChange: func(c []Change) {
+			changed <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	s.set("rate", "2", nil)
+	s.events <- "rate"
+	select {
+	case c := <-changed:
+		out := []Change{{Path: "Rate", Old: 1, New: 2}}
+		if !reflect.DeepEqual(out, c) {
+			t.Errorf("expected changes to be %v but was %v", out, c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected values to be reloaded")
+	}
+	if max := w.Current().(*testWatchedSettings).Limits.Max; max != 20 {
+		t.Errorf("expected max to be 20 but was %d", max)
+	}
+}
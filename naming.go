@@ -0,0 +1,74 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameFunc derives the key of a field from its name. Fields of nested
+// structs are named after their path, e.g. DB.Host.
+type NameFunc func(fieldName string) string
+
+// UpperSnakeCase converts a field name to upper snake case: JWTSecret becomes JWT_SECRET.
+func UpperSnakeCase(fieldName string) string {
+	return strings.ToUpper(strings.Join(splitWords(fieldName), "_"))
+}
+
+// LowerSnakeCase converts a field name to lower snake case: JWTSecret becomes jwt_secret.
+func LowerSnakeCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// LowerKebabCase converts a field name to lower kebab case: JWTSecret becomes jwt-secret.
+func LowerKebabCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// PrefixedName prepends prefix to the names derived by f,
+// e.g. PrefixedName("/", LowerKebabCase) turns MaxConnections into /max-connections.
+func PrefixedName(prefix string, f NameFunc) NameFunc {
+	return func(fieldName string) string {
+		return prefix + f(fieldName)
+	}
+}
+
+// splitWords splits a Go identifier into words, keeping acronyms together:
+// JWTSecret is split into JWT and Secret. Any character that is not a letter
+// or a digit is treated as a separator.
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		if unicode.IsUpper(r) && startsWord(runes, i) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// startsWord reports whether the upper case rune at i starts a new word: either
+// it follows a lower case letter or a digit (maxConns), or it's the last letter
+// of an acronym followed by a lower case letter (JWTSecret).
+func startsWord(runes []rune, i int) bool {
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestNameFuncs(t *testing.T) {
+	testCases := []struct {
+		fieldName  string
+		upperSnake string
+		lowerSnake string
+		lowerKebab string
+	}{
+		{fieldName: "MaxConnections", upperSnake: "MAX_CONNECTIONS", lowerSnake: "max_connections", lowerKebab: "max-connections"},
+		{fieldName: "JWTSecret", upperSnake: "JWT_SECRET", lowerSnake: "jwt_secret", lowerKebab: "jwt-secret"},
+		{fieldName: "BaseURL", upperSnake: "BASE_URL", lowerSnake: "base_url", lowerKebab: "base-url"},
+		{fieldName: "HTTP2Server", upperSnake: "HTTP2_SERVER", lowerSnake: "http2_server", lowerKebab: "http2-server"},
+		{fieldName: "S3Bucket", upperSnake: "S3_BUCKET", lowerSnake: "s3_bucket", lowerKebab: "s3-bucket"},
+		{fieldName: "ID", upperSnake: "ID", lowerSnake: "id", lowerKebab: "id"},
+		{fieldName: "port", upperSnake: "PORT", lowerSnake: "port", lowerKebab: "port"},
+		{fieldName: "DB.Host", upperSnake: "DB_HOST", lowerSnake: "db_host", lowerKebab: "db-host"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.fieldName, func(t *testing.T) {
+			if v := UpperSnakeCase(tC.fieldName); v != tC.upperSnake {
+				t.Errorf("expected UpperSnakeCase to be '%s' but was '%s'", tC.upperSnake, v)
+			}
+			if v := LowerSnakeCase(tC.fieldName); v != tC.lowerSnake {
+				t.Errorf("expected LowerSnakeCase to be '%s' but was '%s'", tC.lowerSnake, v)
+			}
+			if v := LowerKebabCase(tC.fieldName); v != tC.lowerKebab {
+				t.Errorf("expected LowerKebabCase to be '%s' but was '%s'", tC.lowerKebab, v)
+			}
+		})
+	}
+}
+
+func TestPrefixedName(t *testing.T) {
+	f := PrefixedName("/", LowerKebabCase)
+	if v := f("MaxConnections"); v != "/max-connections" {
+		t.Errorf("expected name to be '%s' but was '%s'", "/max-connections", v)
+	}
+}
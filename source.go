@@ -11,6 +11,60 @@ type Source interface {
 	Get(tag TagValue) (string, error)
 }
 
+// LookupSource is a Source that can tell a value that is set but empty
+// apart from a value that is not set at all.
+type LookupSource interface {
+	Source
+	// Lookup returns the value for the key and whether it was found.
+	Lookup(tag TagValue) (string, bool, error)
+}
+
+// lookupValue gets the value for the tag from s. Sources that don't implement
+// LookupSource can't provide empty values.
+func lookupValue(s Source, tag TagValue) (string, bool, error) {
+	if ls, ok := s.(LookupSource); ok {
+		return ls.Lookup(tag)
+	}
+	v, err := s.Get(tag)
+	return v, v != "", err
+}
+
+// KeySource is a Source that reads a different key than the name in the tag,
+// e.g. after adding a prefix.
+type KeySource interface {
+	Source
+	// Key returns the key that is read for the tag.
+	Key(tag TagValue) string
+}
+
+// sourceKey returns the key that s reads for the tag.
+func sourceKey(s Source, tag TagValue) string {
+	if ks, ok := s.(KeySource); ok {
+		return ks.Key(tag)
+	}
+	return tag.Name
+}
+
+// WatchableSource is a Source that notifies when values change,
+// so that a Watcher can reload them as soon as they do.
+type WatchableSource interface {
+	Source
+	// Watch sends the names of the keys whose value changed, as they appear
+	// in the tags, to events until stop is closed. It blocks until then,
+	// or until watching fails.
+	Watch(stop <-chan struct{}, events chan<- string) error
+}
+
+// SnapshotSource is a Source that reads all its values at once, e.g. a remote document.
+// The Loader takes a snapshot the first time a field needs the source, and reads all
+// the other fields of the same load from it, so that they come from the same version.
+type SnapshotSource interface {
+	Source
+	// Snapshot returns a Source with the current values. It can return
+	// the source itself when there's nothing to read in advance.
+	Snapshot() (Source, error)
+}
+
 type source struct {
 	tag string
 	get Getter
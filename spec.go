@@ -0,0 +1,216 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DescTag is the name of the tag describing a field in the documentation: desc:"Maximum number of connections".
+const DescTag = "desc"
+
+// FieldSpec describes how a field is loaded.
+type FieldSpec struct {
+	// Path is the path of the field, e.g. DB.Host.
+	Path string `json:"path"`
+	// Type is the Go type of the field.
+	Type string `json:"type"`
+	// Keys are the keys the field is loaded from, in the order of the sources.
+	Keys []SourceKey `json:"keys"`
+	// Default is the value of the default tag.
+	Default string `json:"default,omitempty"`
+	// Required is true when loading fails if no source provides a value.
+	Required bool `json:"required"`
+	// Secret is true when the field is tagged with secret:"true" or with the ssm secure flag.
+	Secret bool `json:"secret,omitempty"`
+	// Description is the value of the desc tag.
+	Description string `json:"description,omitempty"`
+}
+
+// SourceKey is the key a field is loaded from for a source.
+type SourceKey struct {
+	// Tag is the tag of the source.
+	Tag string `json:"tag"`
+	// Key is the key read by the source, e.g. including the prefix of the env source.
+	Key string `json:"key"`
+	// Secure is true when the tag has the secure flag.
+	Secure bool `json:"secure,omitempty"`
+}
+
+// Spec describes how the fields of v, which must be a struct or a pointer to a struct,
+// are loaded, following the same rules as Load. Fields that are not loaded from any
+// source are not included, and only the sources allowed by the from tag are listed.
+func (c *Loader) Spec(v interface{}) ([]FieldSpec, error) {
+	rt := reflect.TypeOf(v)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, errors.New("config: v is not a struct")
+	}
+	var specs []FieldSpec
+	err := c.specStruct(reflect.New(rt).Elem(), "", &specs)
+	return specs, err
+}
+
+func (c *Loader) specStruct(rv reflect.Value, path string, specs *[]FieldSpec) error {
+	rt := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		f := field{StructField: rt.Field(i), path: rt.Field(i).Name}
+		if path != "" {
+			f.path = path + "." + f.path
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct && fv.CanSet() && !c.hasSourceTag(f) {
+			err := c.specStruct(fv, f.path, specs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		spec, err := c.fieldSpec(fv, f)
+		if err != nil {
+			return err
+		}
+		if len(spec.Keys) > 0 {
+			*specs = append(*specs, spec)
+		}
+	}
+	return nil
+}
+
+func (c *Loader) fieldSpec(fv reflect.Value, f field) (FieldSpec, error) {
+	spec := FieldSpec{
+		Path:        f.path,
+		Type:        f.Type.String(),
+		Secret:      isSecret(f.StructField),
+		Description: f.Tag.Get(DescTag),
+	}
+	optional := false
+	matchedTags := 0
+	allowed, err := allowedSources(f)
+	if err != nil {
+		return spec, err
+	}
+	for _, s := range c.sources {
+		tag, found := c.fieldTag(f, s)
+		if !found {
+			continue
+		}
+		// Like loadFieldValue, count the sources not allowed by the from tag too.
+		matchedTags++
+		optional = tag.HasFlag("optional")
+		if allowed != nil && indexOf(allowed, s.Tag()) < 0 {
+			continue
+		}
+		spec.Keys = append(spec.Keys, SourceKey{
+			Tag:    s.Tag(),
+			Key:    sourceKey(s, tag),
+			Secure: tag.HasFlag("secure"),
+		})
+	}
+	if len(spec.Keys) == 0 {
+		return spec, nil
+	}
+	if _, err := getFieldSetter(fv, f); err != nil {
+		return spec, err
+	}
+	def, hasDefault := f.Tag.Lookup("default")
+	if allowed != nil {
+		// The default value is not used for fields with a from tag.
+		def, hasDefault = "", false
+	}
+	spec.Default = def
+	// The deprecated optional flag is only supported with a single source, see loadFieldValue.
+	spec.Required = !hasDefault && !(matchedTags == 1 && optional)
+	return spec, nil
+}
+
+// WriteSpecJSON writes the specs to w as a JSON array.
+func WriteSpecJSON(w io.Writer, specs []FieldSpec) error {
+	if specs == nil {
+		specs = []FieldSpec{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(specs)
+}
+
+// WriteSpecMarkdown writes the specs to w as a Markdown table,
+// with a column for each source.
+func WriteSpecMarkdown(w io.Writer, specs []FieldSpec) error {
+	tags := specTags(specs)
+	header := append(append([]string{"Field", "Type"}, tags...), "Default", "Required", "Description")
+	rows := [][]string{header, make([]string, len(header))}
+	for i := range header {
+		rows[1][i] = "---"
+	}
+	for _, spec := range specs {
+		row := []string{spec.Path, "`" + spec.Type + "`"}
+		for _, tag := range tags {
+			row = append(row, markdownKey(spec, tag))
+		}
+		def := ""
+		if !spec.Required {
+			def = "`" + strconv.Quote(spec.Default) + "`"
+		}
+		required := "no"
+		if spec.Required {
+			required = "yes"
+		}
+		row = append(row, def, required, spec.Description)
+		rows = append(rows, row)
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] = strings.Replace(row[i], "|", `\|`, -1)
+		}
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// specTags returns the tags of the sources used by the specs,
+// in the order of the sources.
+func specTags(specs []FieldSpec) []string {
+	var tags []string
+	for _, spec := range specs {
+		// Insert the tags that haven't been seen yet after the previous key of the spec.
+		pos := 0
+		for _, k := range spec.Keys {
+			i := indexOf(tags, k.Tag)
+			if i < 0 {
+				tags = append(tags[:pos], append([]string{k.Tag}, tags[pos:]...)...)
+				i = pos
+			}
+			pos = i + 1
+		}
+	}
+	return tags
+}
+
+func indexOf(values []string, v string) int {
+	for i := range values {
+		if values[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func markdownKey(spec FieldSpec, tag string) string {
+	k, found := specKey(spec, tag)
+	if !found {
+		return ""
+	}
+	if k.Secure {
+		return "`" + k.Key + "` (secure)"
+	}
+	return "`" + k.Key + "`"
+}
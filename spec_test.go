@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type testSpecSettings struct {
+	Host     string `env:"HOST" desc:"Host name"`
+	Port     int    `env:"PORT" default:"80"`
+	Password string `ssm:"/$stage/password,secure" env:"PASSWORD"`
+	Legacy   string `env:"LEGACY,optional"`
+	Other    string `json:"other"`
+	DB       struct {
+		Name string `env:"DB_NAME" secret:"true"`
+	}
+}
+
+func testSpecLoader() *Loader {
+	return NewLoader(
+		NewSSMSourceWithConfig(SSMSourceConfig{Substitutions: map[string]string{"stage": "prod"}}),
+		NewEnvSourceWithConfig(EnvSourceConfig{Prefix: "APP_"}),
+	)
+}
+
+func TestSpec(t *testing.T) {
+	specs, err := testSpecLoader().Spec(testSpecSettings{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []FieldSpec{
+		{
+			Path:        "Host",
+			Type:        "string",
+			Keys:        []SourceKey{{Tag: "env", Key: "APP_HOST"}},
+			Required:    true,
+			Description: "Host name",
+		},
+		{
+			Path:    "Port",
+			Type:    "int",
+			Keys:    []SourceKey{{Tag: "env", Key: "APP_PORT"}},
+			Default: "80",
+		},
+		{
+			Path: "Password",
+			Type: "string",
+			Keys: []SourceKey{
+				{Tag: "ssm", Key: "/prod/password", Secure: true},
+				{Tag: "env", Key: "APP_PASSWORD"},
+			},
+			Required: true,
+			Secret:   true,
+		},
+		{
+			Path: "Legacy",
+			Type: "string",
+			Keys: []SourceKey{{Tag: "env", Key: "APP_LEGACY"}},
+		},
+		{
+			Path:     "DB.Name",
+			Type:     "string",
+			Keys:     []SourceKey{{Tag: "env", Key: "APP_DB_NAME"}},
+			Required: true,
+			Secret:   true,
+		},
+	}
+	if !reflect.DeepEqual(out, specs) {
+		t.Errorf("expected specs to be %+v but was %+v", out, specs)
+	}
+}
+
+func TestSpecErrors(t *testing.T) {
+	_, err := NewLoader(NewEnvSource()).Spec("test")
+	if err == nil || err.Error() != "config: v is not a struct" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = NewLoader(NewEnvSource()).Spec(&struct {
+		T error `env:"T"`
+	}{})
+	if err == nil || err.Error() != "config: field type error is not supported" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+}
+
+func TestWriteSpecMarkdown(t *testing.T) {
+	specs, err := testSpecLoader().Spec(&testSpecSettings{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	err = WriteSpecMarkdown(&buf, specs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := "| Field | Type | ssm | env | Default | Required | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| Host | `string` |  | `APP_HOST` |  | yes | Host name |\n" +
+		"| Port | `int` |  | `APP_PORT` | `\"80\"` | no |  |\n" +
+		"| Password | `string` | `/prod/password` (secure) | `APP_PASSWORD` |  | yes |  |\n" +
+		"| Legacy | `string` |  | `APP_LEGACY` | `\"\"` | no |  |\n" +
+		"| DB.Name | `string` |  | `APP_DB_NAME` |  | yes |  |\n"
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestWriteSpecJSON(t *testing.T) {
+	specs := []FieldSpec{{
+		Path:     "Host",
+		Type:     "string",
+		Keys:     []SourceKey{{Tag: "env", Key: "HOST"}},
+		Required: true,
+	}}
+	var buf bytes.Buffer
+	err := WriteSpecJSON(&buf, specs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := `[
+  {
+    "path": "Host",
+    "type": "string",
+    "keys": [
+      {
+        "tag": "env",
+        "key": "HOST"
+      }
+    ],
+    "required": true
+  }
+]
+`
+	if buf.String() != out {
+		t.Errorf("expected output to be\n%s\nbut was\n%s", out, buf.String())
+	}
+}
+
+func TestSpecFromTag(t *testing.T) {
+	specs, err := testSpecLoader().Spec(&struct {
+		Password string `ssm:"/password" env:"PASSWORD" default:"default" from:"ssm"`
+		Key      string `env:"KEY" from:"ssm"`
+	}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []FieldSpec{
+		{
+			Path:     "Password",
+			Type:     "string",
+			Keys:     []SourceKey{{Tag: "ssm", Key: "/password"}},
+			Required: true,
+		},
+	}
+	if !reflect.DeepEqual(out, specs) {
+		t.Errorf("expected specs to be %+v but was %+v", out, specs)
+	}
+	// The deprecated optional flag only applies to fields with a single source,
+	// including the ones not allowed by the from tag.
+	specs, err = testSpecLoader().Spec(&struct {
+		X string `env:"X" ssm:"X,optional" from:"ssm"`
+	}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(specs) != 1 || !specs[0].Required {
+		t.Errorf("expected X to be required but was %+v", specs)
+	}
+
+	_, err = testSpecLoader().Spec(&struct {
+		Key string `env:"KEY" from:""`
+	}{})
+	if err == nil || err.Error() != "config: invalid from tag for field 'Key': no sources" {
+		t.Errorf("expected error to be 'config: invalid from tag for field 'Key': no sources' but was '%v'", err)
+	}
+}
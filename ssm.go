@@ -30,18 +30,22 @@ type ssmSource struct {
 	subs map[string]string
 }
 
-var _ssmSourceIfaceCheck Source = &ssmSource{}
+var _ssmSourceIfaceCheck KeySource = &ssmSource{}
 
 func (s *ssmSource) Tag() string {
 	return SSMTag
 }
 
-func (s *ssmSource) Get(tag TagValue) (string, error) {
-	name := tag.Name
-	withDecryption := tag.HasFlag("secure")
+func (s *ssmSource) Key(tag TagValue) string {
 	if s.subs != nil {
-		name = getParamName(tag.Name, s.subs)
+		return getParamName(tag.Name, s.subs)
 	}
+	return tag.Name
+}
+
+func (s *ssmSource) Get(tag TagValue) (string, error) {
+	name := s.Key(tag)
+	withDecryption := tag.HasFlag("secure")
 	out, err := s.svc.GetParameter(&ssm.GetParameterInput{
 		Name:           &name,
 		WithDecryption: &withDecryption,
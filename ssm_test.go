@@ -57,8 +57,8 @@ func TestSSMSource(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected to get an error, got nil")
 	}
-	if err.Error() != "failed" {
-		t.Fatalf("expected to get error message 'failed', got: '%s'", err.Error())
+	if err.Error() != "config: error loading field TestParameter for tag ssm: failed" {
+		t.Fatalf("unexpected error: '%s'", err.Error())
 	}
 
 	// Test when SSM.GetParameter can't find parameter
@@ -77,7 +77,7 @@ func TestSSMSource(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected to get an error, got nil")
 	}
-	if err.Error() != "config: missing value for key 'test/parameter/name'" {
+	if err.Error() != "config: missing value for field 'TestParameter'" {
 		t.Fatalf("unexpected error: '%s'", err.Error())
 	}
 }
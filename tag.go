@@ -16,10 +16,10 @@ func (t TagValue) HasFlag(f string) bool {
 
 func newTagValue(tag, fieldName string) TagValue {
 	bits := strings.Split(tag, ",")
-	if len(bits) == 0 || bits[0] == "" {
-		return TagValue{Name: fieldName}
-	}
 	t := TagValue{Name: bits[0]}
+	if t.Name == "" {
+		t.Name = fieldName
+	}
 	if len(bits) > 1 {
 		t.flags = make(map[string]struct{})
 		for _, k := range bits[1:] {
@@ -17,3 +17,13 @@ func TestTagValue(t *testing.T) {
 		t.Fatalf("did not expected to find the 'delicious' flag")
 	}
 }
+
+func TestTagValueWithoutName(t *testing.T) {
+	tag := newTagValue(",secure", "X")
+	if tag.Name != "X" {
+		t.Fatalf("expected tag name to be 'X', but got %s", tag.Name)
+	}
+	if found := tag.HasFlag("secure"); !found {
+		t.Fatalf("expected to find the 'secure' flag")
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validation checks the value of a field against the argument of its tag.
+// It returns a description of the violation, or an error if the tag is invalid.
+type validation func(fv reflect.Value, arg string) (violation string, err error)
+
+// validations are evaluated in order, after the value of the field has been set.
+var validations = []struct {
+	tag      string
+	validate validation
+}{
+	{tag: "nonempty", validate: validateNonEmpty},
+	{tag: "len", validate: validateLen},
+	{tag: "min", validate: validateMin},
+	{tag: "max", validate: validateMax},
+	{tag: "oneof", validate: validateOneOf},
+	{tag: "pattern", validate: validatePattern},
+}
+
+// validateField validates the value of the field. If set is false, because neither a source
+// nor the default provided a value, only nonempty is checked: the zero value is not validated.
+func validateField(fv reflect.Value, f field, set bool) error {
+	for _, v := range validations {
+		arg, found := f.Tag.Lookup(v.tag)
+		if !found || (!set && v.tag != "nonempty") {
+			continue
+		}
+		violation, err := v.validate(fv, arg)
+		if err != nil {
+			return fmt.Errorf("config: invalid %s tag for field '%s': %v", v.tag, f.path, err)
+		}
+		if violation != "" {
+			return invalidValueError(f.path, violation)
+		}
+	}
+	return nil
+}
+
+func invalidValueError(fieldName, violation string) error {
+	return fmt.Errorf("config: invalid value for field '%s': %s", fieldName, violation)
+}
+
+func validateNonEmpty(fv reflect.Value, arg string) (string, error) {
+	enabled, err := strconv.ParseBool(arg)
+	if err != nil {
+		return "", err
+	}
+	if enabled && fv.Interface() == reflect.Zero(fv.Type()).Interface() {
+		return "must not be empty", nil
+	}
+	return "", nil
+}
+
+func validateLen(fv reflect.Value, arg string) (string, error) {
+	if fv.Kind() != reflect.String {
+		return "", fmt.Errorf("field type %s is not supported", fv.Type().Name())
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return "", err
+	}
+	if utf8.RuneCountInString(fv.String()) != n {
+		return fmt.Sprintf("must be %d characters long", n), nil
+	}
+	return "", nil
+}
+
+func validateMin(fv reflect.Value, arg string) (string, error) {
+	c, err := compareTo(fv, arg)
+	if err != nil || c >= 0 {
+		return "", err
+	}
+	if fv.Kind() == reflect.String {
+		return fmt.Sprintf("must be at least %s characters long", arg), nil
+	}
+	return fmt.Sprintf("must be at least %s", arg), nil
+}
+
+func validateMax(fv reflect.Value, arg string) (string, error) {
+	c, err := compareTo(fv, arg)
+	if err != nil || c <= 0 {
+		return "", err
+	}
+	if fv.Kind() == reflect.String {
+		return fmt.Sprintf("must be at most %s characters long", arg), nil
+	}
+	return fmt.Sprintf("must be at most %s", arg), nil
+}
+
+// compareTo compares the value of a numeric field, or the length of a string field,
+// to arg. The result is -1 if it's less than arg, 0 if it's equal and 1 if it's greater.
+func compareTo(fv reflect.Value, arg string) (int, error) {
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt64(fv.Int(), n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		v := fv.Uint()
+		if v < n {
+			return -1, nil
+		}
+		if v > n {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.String:
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compareInt64(int64(utf8.RuneCountInString(fv.String())), n), nil
+	}
+	return 0, fmt.Errorf("field type %s is not supported", fv.Type().Name())
+}
+
+func compareInt64(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func validateOneOf(fv reflect.Value, arg string) (string, error) {
+	v := fmt.Sprint(fv.Interface())
+	for _, allowed := range strings.Fields(arg) {
+		if v == allowed {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", ")), nil
+}
+
+func validatePattern(fv reflect.Value, arg string) (string, error) {
+	re, err := regexp.Compile(arg)
+	if err != nil {
+		return "", err
+	}
+	if !re.MatchString(fmt.Sprint(fv.Interface())) {
+		return fmt.Sprintf("must match %s", arg), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,165 @@
+package config
+
+import "testing"
+
+func Test_Validation(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		v      interface{}
+		values map[string]string
+		err    string
+	}{
+		{
+			desc: "valid values",
+			v: &struct {
+				Port     int    `test:"port" min:"1" max:"65535"`
+				Workers  uint8  `test:"workers" min:"1" max:"16"`
+				LogLevel string `test:"level" oneof:"debug info warn error"`
+				URL      string `test:"url" pattern:"^https://"`
+				Code     string `test:"code" len:"3" nonempty:"true"`
+				Name     string `test:"name" min:"2" max:"5"`
+				Opt      string `test:"opt" default:"" nonempty:"false"`
+			}{},
+			values: map[string]string{
+				"port":    "8080",
+				"workers": "4",
+				"level":   "info",
+				"url":     "https://example.com",
+				"code":    "àbc",
+				"name":    "rome",
+				"opt":     "",
+			},
+		},
+		{
+			desc: "unset optional value",
+			v: &struct {
+				LogLevel string `test:"level,optional" oneof:"debug info"`
+			}{},
+			values: map[string]string{"level": ""},
+		},
+		{
+			desc: "unset optional number",
+			v: &struct {
+				Port int `test:"port,optional" min:"1"`
+			}{},
+			values: map[string]string{"port": ""},
+		},
+		{
+			desc: "unset optional value with nonempty",
+			v: &struct {
+				LogLevel string `test:"level,optional" nonempty:"true"`
+			}{},
+			values: map[string]string{"level": ""},
+			err:    "config: invalid value for field 'LogLevel': must not be empty",
+		},
+		{
+			desc: "below min",
+			v: &struct {
+				Port int `test:"port" min:"1"`
+			}{},
+			values: map[string]string{"port": "-1"},
+			err:    "config: invalid value for field 'Port': must be at least 1",
+		},
+		{
+			desc: "above max",
+			v: &struct {
+				Workers uint `test:"workers" max:"16"`
+			}{},
+			values: map[string]string{"workers": "32"},
+			err:    "config: invalid value for field 'Workers': must be at most 16",
+		},
+		{
+			desc: "string longer than max",
+			v: &struct {
+				Name string `test:"name" max:"5"`
+			}{},
+			values: map[string]string{"name": "venice"},
+			err:    "config: invalid value for field 'Name': must be at most 5 characters long",
+		},
+		{
+			desc: "string shorter than min",
+			v: &struct {
+				Name string `test:"name" min:"2"`
+			}{},
+			values: map[string]string{"name": "a"},
+			err:    "config: invalid value for field 'Name': must be at least 2 characters long",
+		},
+		{
+			desc: "not one of the allowed values",
+			v: &struct {
+				LogLevel string `test:"level" oneof:"debug info"`
+			}{},
+			values: map[string]string{"level": "trace"},
+			err:    "config: invalid value for field 'LogLevel': must be one of debug, info",
+		},
+		{
+			desc: "pattern does not match",
+			v: &struct {
+				URL string `test:"url" pattern:"^https://"`
+			}{},
+			values: map[string]string{"url": "http://example.com"},
+			err:    "config: invalid value for field 'URL': must match ^https://",
+		},
+		{
+			desc: "wrong length",
+			v: &struct {
+				Code string `test:"code" len:"3"`
+			}{},
+			values: map[string]string{"code": "abcd"},
+			err:    "config: invalid value for field 'Code': must be 3 characters long",
+		},
+		{
+			desc: "empty",
+			v: &struct {
+				Key string `test:"key" default:"" nonempty:"true"`
+			}{},
+			values: map[string]string{"key": ""},
+			err:    "config: invalid value for field 'Key': must not be empty",
+		},
+		{
+			desc: "default is validated",
+			v: &struct {
+				Port int `test:"port" default:"0" min:"1"`
+			}{},
+			values: map[string]string{"port": ""},
+			err:    "config: invalid value for field 'Port': must be at least 1",
+		},
+		{
+			desc: "invalid tag",
+			v: &struct {
+				Port int `test:"port" min:"one"`
+			}{},
+			values: map[string]string{"port": "1"},
+			err:    `config: invalid min tag for field 'Port': strconv.ParseInt: parsing "one": invalid syntax`,
+		},
+		{
+			desc: "unsupported field type",
+			v: &struct {
+				Debug bool `test:"debug" len:"1"`
+			}{},
+			values: map[string]string{"debug": "true"},
+			err:    "config: invalid len tag for field 'Debug': field type bool is not supported",
+		},
+		{
+			desc: "invalid pattern",
+			v: &struct {
+				URL string `test:"url" pattern:"("`
+			}{},
+			values: map[string]string{"url": "x"},
+			err:    "config: invalid pattern tag for field 'URL': error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			l := NewLoader(&testSource{values: tC.values})
+			err := l.Load(tC.v)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultTag is the name of the tag to load values from HashiCorp Vault.
+// The key is the API path of the secret and the name of the field, separated
+// by #: vault:"secret/data/app#password".
+const VaultTag = "vault"
+
+// DefaultVaultCacheTTL is how long secrets are cached, unless their lease is shorter.
+const DefaultVaultCacheTTL = time.Minute
+
+// VaultSourceConfig is the configuration for the creation of a Vault Source.
+type VaultSourceConfig struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200.
+	// Defaults to the VAULT_ADDR environment variable.
+	Address string
+	// Token authenticates the requests. Defaults to the VAULT_TOKEN
+	// environment variable, unless AppRole is set.
+	Token string
+	// AppRole authenticates with the AppRole method when Token is empty.
+	AppRole *VaultAppRole
+	// KVVersion is the version of the KV secrets engine, 1 or 2. Defaults to 2.
+	KVVersion int
+	// CacheTTL is how long secrets are cached, or less if their lease is shorter.
+	// Defaults to DefaultVaultCacheTTL. Use a negative value to disable caching.
+	CacheTTL time.Duration
+	// Client sends the requests. Defaults to a client with a 10 seconds timeout.
+	Client *http.Client
+}
+
+// VaultAppRole are the credentials for the AppRole auth method.
+type VaultAppRole struct {
+	RoleID   string
+	SecretID string
+	// MountPath is the path the method is mounted at. Defaults to approle.
+	MountPath string
+}
+
+// NewVaultSource creates a Source for values stored in Vault,
+// using the VAULT_ADDR and VAULT_TOKEN environment variables.
+func NewVaultSource() Source {
+	return NewVaultSourceWithConfig(VaultSourceConfig{})
+}
+
+// NewVaultSourceWithConfig creates a Source for values stored in Vault specifying custom configuration.
+func NewVaultSourceWithConfig(cfg VaultSourceConfig) Source {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" && cfg.AppRole == nil {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.AppRole != nil && cfg.AppRole.MountPath == "" {
+		appRole := *cfg.AppRole
+		appRole.MountPath = "approle"
+		cfg.AppRole = &appRole
+	}
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = 2
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = DefaultVaultCacheTTL
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &vaultSource{
+		cfg:   cfg,
+		token: cfg.Token,
+		cache: make(map[string]vaultSecret),
+		now:   time.Now,
+	}
+}
+
+type vaultSource struct {
+	cfg VaultSourceConfig
+	now func() time.Time
+
+	mu           sync.Mutex
+	token        string
+	tokenExpires time.Time
+	cache        map[string]vaultSecret
+}
+
+// vaultSecret is a cached secret.
+type vaultSecret struct {
+	values  map[string]string
+	found   bool
+	expires time.Time
+}
+
+var _vaultSourceIfaceCheck LookupSource = &vaultSource{}
+
+func (s *vaultSource) Tag() string {
+	return VaultTag
+}
+
+func (s *vaultSource) Get(tag TagValue) (string, error) {
+	v, _, err := s.Lookup(tag)
+	return v, err
+}
+
+func (s *vaultSource) Lookup(tag TagValue) (string, bool, error) {
+	bits := strings.SplitN(tag.Name, "#", 2)
+	if len(bits) != 2 || bits[0] == "" || bits[1] == "" {
+		return "", false, fmt.Errorf("config: vault key %s is not in the form path#field", tag.Name)
+	}
+	path, name := strings.Trim(bits[0], "/"), bits[1]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, cached := s.cache[path]
+	if !cached || !s.now().Before(secret.expires) {
+		var err error
+		secret, err = s.readSecret(path)
+		if err != nil {
+			return "", false, err
+		}
+		if s.cfg.CacheTTL > 0 {
+			s.cache[path] = secret
+		}
+	}
+	v, found := secret.values[name]
+	return v, found, nil
+}
+
+// vaultResponse is the body of the responses of the Vault API.
+type vaultResponse struct {
+	LeaseDuration int             `json:"lease_duration"`
+	Data          json.RawMessage `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+func (s *vaultSource) readSecret(path string) (vaultSecret, error) {
+	if err := s.login(); err != nil {
+		return vaultSecret{}, err
+	}
+	resp, status, err := s.do(http.MethodGet, path, nil)
+	if err != nil {
+		return vaultSecret{}, err
+	}
+	secret := vaultSecret{expires: s.now().Add(s.cfg.CacheTTL)}
+	if status == http.StatusNotFound {
+		return secret, nil
+	}
+	// KV v1 leases default to 32 days, so they only shorten the cache.
+	if lease := time.Duration(resp.LeaseDuration) * time.Second; lease > 0 && lease < s.cfg.CacheTTL {
+		secret.expires = s.now().Add(lease)
+	}
+	data := resp.Data
+	if s.cfg.KVVersion == 2 {
+		var v2 struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &v2); err != nil {
+			return vaultSecret{}, fmt.Errorf("config: invalid response from vault for %s: %v", path, err)
+		}
+		data = v2.Data
+	}
+	secret.values, err = vaultValues(data)
+	if err != nil {
+		return vaultSecret{}, fmt.Errorf("config: invalid response from vault for %s: %v", path, err)
+	}
+	secret.found = true
+	return secret, nil
+}
+
+// vaultValues converts the fields of a secret to strings, keeping
+// the JSON representation of the values that are not strings.
+func vaultValues(data json.RawMessage) (map[string]string, error) {
+	var fields map[string]json.RawMessage
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(fields))
+	for k, raw := range fields {
+		var str string
+		if err := json.Unmarshal(raw, &str); err == nil {
+			values[k] = str
+		} else {
+			values[k] = string(raw)
+		}
+	}
+	return values, nil
+}
+
+// login gets a token with AppRole, if configured and the current token expired.
+func (s *vaultSource) login() error {
+	if s.cfg.AppRole == nil || (s.token != "" && (s.tokenExpires.IsZero() || s.now().Before(s.tokenExpires))) {
+		return nil
+	}
+	s.token = ""
+	body, _ := json.Marshal(map[string]string{
+		"role_id":   s.cfg.AppRole.RoleID,
+		"secret_id": s.cfg.AppRole.SecretID,
+	})
+	resp, status, err := s.do(http.MethodPost, "auth/"+strings.Trim(s.cfg.AppRole.MountPath, "/")+"/login", body)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound || resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("config: vault login with approle returned no token")
+	}
+	s.token = resp.Auth.ClientToken
+	s.tokenExpires = time.Time{}
+	if resp.Auth.LeaseDuration > 0 {
+		s.tokenExpires = s.now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	}
+	return nil
+}
+
+// do sends a request to the Vault API. Not found responses are not errors.
+func (s *vaultSource) do(method, path string, body []byte) (*vaultResponse, int, error) {
+	url := strings.TrimRight(s.cfg.Address, "/") + "/v1/" + path
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+	res, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+	var resp vaultResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil && err != io.EOF {
+		return nil, res.StatusCode, fmt.Errorf("config: invalid response from vault for %s: %v", path, err)
+	}
+	if res.StatusCode == http.StatusNotFound && len(resp.Errors) == 0 {
+		return &resp, res.StatusCode, nil
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if res.StatusCode == http.StatusForbidden && s.cfg.AppRole != nil {
+			// Get a new token with the next request.
+			s.token = ""
+		}
+		return nil, res.StatusCode, fmt.Errorf("config: vault returned %d for %s: %s", res.StatusCode, path, strings.Join(resp.Errors, ", "))
+	}
+	return &resp, res.StatusCode, nil
+}
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testVault is a stand-in for the Vault API.
+type testVault struct {
+	mu       sync.Mutex
+	secrets  map[string]string
+	leases   map[string]int
+	tokens   map[string]bool
+	logins   int
+	requests map[string]int
+}
+
+func newTestVault() *testVault {
+	return &testVault{
+		secrets:  map[string]string{},
+		leases:   map[string]int{},
+		tokens:   map[string]bool{"root": true},
+		requests: map[string]int{},
+	}
+}
+
+func (v *testVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	v.requests[path]++
+	if path == "auth/approle/login" {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		v.logins++
+		token := "approle-token"
+		v.tokens[token] = true
+		w.Write([]byte(`{"auth":{"client_token":"` + token + `","lease_duration":60}}`))
+		return
+	}
+	if !v.tokens[r.Header.Get("X-Vault-Token")] {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	secret, found := v.secrets[path]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+	w.Write([]byte(`{"lease_duration":` + jsonInt(v.leases[path]) + `,"data":` + secret + `}`))
+}
+
+func (v *testVault) count(path string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.requests[path]
+}
+
+func jsonInt(i int) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
+
+func TestVaultSource(t *testing.T) {
+	vault := newTestVault()
+	vault.secrets["secret/data/app"] = `{"data":{"password":"s3cret","port":5432,"empty":""},"metadata":{"version":3}}`
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	s := NewVaultSourceWithConfig(VaultSourceConfig{
+		Address: server.URL,
+		Token:   "root",
+	})
+	if s.Tag() != "vault" {
+		t.Errorf("expected tag to be '%s' but was '%s'", "vault", s.Tag())
+	}
+
+	testCases := []struct {
+		desc  string
+		name  string
+		out   string
+		found bool
+		err   string
+	}{
+		{
+			desc:  "string value",
+			name:  "secret/data/app#password",
+			out:   "s3cret",
+			found: true,
+		},
+		{
+			desc:  "number value",
+			name:  "secret/data/app#port",
+			out:   "5432",
+			found: true,
+		},
+		{
+			desc:  "empty value",
+			name:  "secret/data/app#empty",
+			out:   "",
+			found: true,
+		},
+		{
+			desc: "missing field",
+			name: "secret/data/app#missing",
+		},
+		{
+			desc: "missing secret",
+			name: "secret/data/missing#password",
+		},
+		{
+			desc: "missing field name",
+			name: "secret/data/app",
+			err:  "config: vault key secret/data/app is not in the form path#field",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			out, found, err := s.(LookupSource).Lookup(newTagValue(tC.name, "Field"))
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%s'", tC.err, errMsg)
+			}
+			if out != tC.out || found != tC.found {
+				t.Fatalf("expected '%s' (found: %v) but was '%s' (found: %v)", tC.out, tC.found, out, found)
+			}
+		})
+	}
+	if n := vault.count("secret/data/app"); n != 1 {
+		t.Fatalf("expected 1 request for the secret but was %d", n)
+	}
+}
+
+func TestVaultSourceKVv1Leases(t *testing.T) {
+	vault := newTestVault()
+	// The default lease of KV v1 is 32 days.
+	vault.secrets["kv/app"] = `{"password":"s3cret"}`
+	vault.leases["kv/app"] = 2764800
+	vault.secrets["kv/short"] = `{"key":"value"}`
+	vault.leases["kv/short"] = 5
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	now := time.Now()
+	s := NewVaultSourceWithConfig(VaultSourceConfig{
+		Address:   server.URL,
+		Token:     "root",
+		KVVersion: 1,
+		CacheTTL:  10 * time.Second,
+	}).(*vaultSource)
+	s.now = func() time.Time { return now }
+
+	get := func(name, expected string) {
+		t.Helper()
+		out, err := s.Get(newTagValue(name, "Field"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != expected {
+			t.Fatalf("expected '%s' but was '%s'", expected, out)
+		}
+	}
+	get("kv/app#password", "s3cret")
+	get("kv/short#key", "value")
+
+	// The lease of kv/short expired, kv/app is still cached.
+	now = now.Add(6 * time.Second)
+	get("kv/app#password", "s3cret")
+	get("kv/short#key", "value")
+	if n := vault.count("kv/app"); n != 1 {
+		t.Fatalf("expected 1 request for kv/app but was %d", n)
+	}
+	if n := vault.count("kv/short"); n != 2 {
+		t.Fatalf("expected 2 requests for kv/short but was %d", n)
+	}
+
+	// The cache of kv/app expires after CacheTTL, even if the lease is longer.
+	now = now.Add(5 * time.Second)
+	get("kv/app#password", "s3cret")
+	if n := vault.count("kv/app"); n != 2 {
+		t.Fatalf("expected 2 requests for kv/app but was %d", n)
+	}
+}
+
+func TestVaultSourceWithoutCache(t *testing.T) {
+	vault := newTestVault()
+	vault.secrets["kv/app"] = `{"password":"s3cret"}`
+	vault.leases["kv/app"] = 2764800
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	s := NewVaultSourceWithConfig(VaultSourceConfig{
+		Address:   server.URL,
+		Token:     "root",
+		KVVersion: 1,
+		CacheTTL:  -1,
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := s.Get(newTagValue("kv/app#password", "Field")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := vault.count("kv/app"); n != 2 {
+		t.Fatalf("expected 2 requests for kv/app but was %d", n)
+	}
+}
+
+func TestVaultSourceAppRole(t *testing.T) {
+	vault := newTestVault()
+	vault.secrets["secret/data/app"] = `{"data":{"password":"s3cret"}}`
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	now := time.Now()
+	s := NewVaultSourceWithConfig(VaultSourceConfig{
+		Address:  server.URL,
+		AppRole:  &VaultAppRole{RoleID: "role", SecretID: "secret"},
+		CacheTTL: -1,
+	}).(*vaultSource)
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		out, err := s.Get(newTagValue("secret/data/app#password", "Field"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out != "s3cret" {
+			t.Fatalf("expected '%s' but was '%s'", "s3cret", out)
+		}
+	}
+	if vault.logins != 1 {
+		t.Fatalf("expected 1 login but was %d", vault.logins)
+	}
+
+	// The token expired.
+	now = now.Add(time.Minute)
+	if _, err := s.Get(newTagValue("secret/data/app#password", "Field")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vault.logins != 2 {
+		t.Fatalf("expected 2 logins but was %d", vault.logins)
+	}
+}
+
+func TestVaultSourceErrors(t *testing.T) {
+	vault := newTestVault()
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	testCases := []struct {
+		desc string
+		cfg  VaultSourceConfig
+		err  string
+	}{
+		{
+			desc: "invalid token",
+			cfg:  VaultSourceConfig{Token: "invalid"},
+			err:  "config: vault returned 403 for secret/data/app: permission denied",
+		},
+		{
+			desc: "invalid approle",
+			cfg:  VaultSourceConfig{AppRole: &VaultAppRole{RoleID: "role", SecretID: "invalid"}},
+			err:  "config: vault returned 400 for auth/approle/login: invalid role or secret ID",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tC.cfg.Address = server.URL
+			s := NewVaultSourceWithConfig(tC.cfg)
+			_, err := s.Get(newTagValue("secret/data/app#password", "Field"))
+			if err == nil || err.Error() != tC.err {
+				t.Fatalf("expected error to be '%s' but was '%v'", tC.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Change is a field whose value changed when reloading.
+type Change struct {
+	// Path is the path of the field, e.g. DB.Host.
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// WatcherConfig is the configuration for the creation of a Watcher.
+type WatcherConfig struct {
+	// Interval is the time between reloads. Values are only reloaded
+	// on signals or when calling Reload if it's zero.
+	Interval time.Duration
+	// Signals trigger a reload, e.g. syscall.SIGHUP.
+	Signals []os.Signal
+	// OnChange is called after a reload that changed at least one value,
+	// once the new values are available through Current. It can call Reload and Close.
+	OnChange func(changes []Change)
+	// OnError is called when a reload fails. The previous values are kept.
+	// Like OnChange, it can be called from several goroutines at the same time.
+	OnError func(err error)
+}
+
+// Watcher reloads values periodically, or on signals, and notifies the changes.
+// Values from a WatchableSource are reloaded as soon as the source notifies that
+// they changed.
+type Watcher struct {
+	loader  *Loader
+	cfg     WatcherConfig
+	initial reflect.Value
+	current atomic.Value
+	// mu serializes reloads.
+	mu        sync.Mutex
+	sig       chan os.Signal
+	stop      chan struct{}
+	closeOnce sync.Once
+	// active counts the goroutines of the watcher that are not running a callback.
+	activeMu sync.Mutex
+	active   int
+	idle     *sync.Cond
+}
+
+// NewWatcher loads v, which must be a pointer to a struct, and creates a Watcher
+// that keeps reloading it. Every reload loads the values into a new copy of v as
+// it was before the first load, so that the values returned by Current are never modified.
+func NewWatcher(l *Loader, v interface{}, cfg WatcherConfig) (*Watcher, error) {
+	rv, err := getWritableValue(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		loader:  l,
+		cfg:     cfg,
+		initial: reflect.New(rv.Type()).Elem(),
+		stop:    make(chan struct{}),
+	}
+	w.idle = sync.NewCond(&w.activeMu)
+	w.initial.Set(rv)
+	next, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	rv.Set(next.Elem())
+	w.current.Store(next.Interface())
+	if len(cfg.Signals) > 0 {
+		w.sig = make(chan os.Signal, 1)
+		signal.Notify(w.sig, cfg.Signals...)
+	}
+	w.enter()
+	go w.run()
+	for _, s := range l.sources {
+		if ws, ok := s.(WatchableSource); ok {
+			w.enter()
+			go w.watch(ws)
+		}
+	}
+	return w, nil
+}
+
+// Current returns a pointer to the latest values, with the same type as the value
+// passed to NewWatcher. The values must not be modified, since they are shared
+// with all the callers.
+func (w *Watcher) Current() interface{} {
+	return w.current.Load()
+}
+
+// Reload loads the values and replaces the current ones if loading succeeds.
+// OnChange is called if any value changed, but OnError is not called on error.
+func (w *Watcher) Reload() error {
+	changes, err := w.reload()
+	if err != nil {
+		return err
+	}
+	w.notify(changes)
+	return nil
+}
+
+// Close stops reloading the values and waits for the reloads in progress,
+// except for the callbacks that are running. It can be called more than once.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+	})
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	for w.active > 0 {
+		w.idle.Wait()
+	}
+}
+
+func (w *Watcher) reload() ([]Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	return w.swap(next), nil
+}
+
+// swap replaces the current values with next and returns the changes.
+func (w *Watcher) swap(next reflect.Value) []Change {
+	prev := reflect.ValueOf(w.current.Load())
+	w.current.Store(next.Interface())
+	return diffStruct(prev.Elem(), next.Elem(), "", nil)
+}
+
+// notify calls OnChange if there are changes.
+func (w *Watcher) notify(changes []Change) {
+	if len(changes) > 0 && w.cfg.OnChange != nil {
+		w.cfg.OnChange(changes)
+	}
+}
+
+// done reports the result of a reload made by a goroutine of the watcher.
+// Close doesn't wait for the callbacks, so that they can call it.
+func (w *Watcher) done(changes []Change, err error) {
+	if err == nil && len(changes) == 0 || err != nil && w.cfg.OnError == nil {
+		return
+	}
+	w.leave()
+	defer w.enter()
+	if err != nil {
+		w.cfg.OnError(err)
+		return
+	}
+	w.notify(changes)
+}
+
+func (w *Watcher) enter() {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	w.active++
+}
+
+func (w *Watcher) leave() {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	w.active--
+	if w.active == 0 {
+		w.idle.Broadcast()
+	}
+}
+
+// stopped reports whether Close was called.
+func (w *Watcher) stopped() bool {
+	select {
+	case <-w.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// watch reloads the fields loaded from the keys that s notifies about.
+func (w *Watcher) watch(s WatchableSource) {
+	defer w.leave()
+	events := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.Watch(w.stop, events)
+		close(events)
+	}()
+	defer func() {
+		if err := <-errc; err != nil && !w.stopped() {
+			w.done(nil, fmt.Errorf("config: error watching source %s: %v", s.Tag(), err))
+		}
+	}()
+	for name := range events {
+		// Reload once for all the events that are already available.
+		keys := map[string]bool{name: true}
+	drain:
+		for {
+			select {
+			case name, ok := <-events:
+				if !ok {
+					break drain
+				}
+				keys[name] = true
+			default:
+				break drain
+			}
+		}
+		if w.stopped() {
+			continue
+		}
+		w.done(w.reloadKeys(s, keys))
+	}
+}
+
+// reloadKeys reloads the fields loaded from the keys of s, keeping the current
+// values of the other fields.
+func (w *Watcher) reloadKeys(s Source, keys map[string]bool) ([]Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	next := reflect.New(w.initial.Type())
+	next.Elem().Set(reflect.ValueOf(w.current.Load()).Elem())
+	err := w.loader.snapshot().loadStruct(next.Elem(), "", loadOptions{
+		only: func(f field) bool {
+			tag, found := w.loader.fieldTag(f, s)
+			return found && keys[tag.Name]
+		},
+		initial: w.initial,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.swap(next), nil
+}
+
+func (w *Watcher) load() (reflect.Value, error) {
+	next := reflect.New(w.initial.Type())
+	next.Elem().Set(w.initial)
+	err := w.loader.Load(next.Interface())
+	return next, err
+}
+
+func (w *Watcher) run() {
+	defer w.leave()
+	var tick <-chan time.Time
+	if w.cfg.Interval > 0 {
+		t := time.NewTicker(w.cfg.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+	if w.sig != nil {
+		defer signal.Stop(w.sig)
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-tick:
+		case <-w.sig:
+		}
+		if w.stopped() {
+			return
+		}
+		w.done(w.reload())
+	}
+}
+
+// diffStruct appends the exported fields that are different in a and b to changes.
+func diffStruct(a, b reflect.Value, path string, changes []Change) []Change {
+	rt := a.Type()
+	for i := 0; i < a.NumField(); i++ {
+		ft := rt.Field(i)
+		if ft.PkgPath != "" {
+			continue
+		}
+		fieldPath := ft.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		av, bv := a.Field(i), b.Field(i)
+		if av.Kind() == reflect.Struct {
+			changes = diffStruct(av, bv, fieldPath, changes)
+			continue
+		}
+		if !reflect.DeepEqual(av.Interface(), bv.Interface()) {
+			changes = append(changes, Change{Path: fieldPath, Old: av.Interface(), New: bv.Interface()})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMutableSource struct {
+	mu     sync.Mutex
+	values map[string]string
+	err    error
+}
+
+func (ts *testMutableSource) Tag() string {
+	return "test"
+}
+
+func (ts *testMutableSource) Get(tag TagValue) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.values[tag.Name], ts.err
+}
+
+func (ts *testMutableSource) set(key, value string, err error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.values[key] = value
+	ts.err = err
+}
+
+type testWatchedSettings struct {
+	Rate    int  `test:"rate"`
+	Enabled bool `test:"enabled" default:"false"`
+	Limits  struct {
+		Max int `test:"max" default:"10"`
+	}
+	Name string
+}
+
+func TestWatcherReload(t *testing.T) {
+	s := &testMutableSource{values: map[string]string{"rate": "1"}}
+	var changes []Change
+	v := testWatchedSettings{Name: "worker"}
+	w, err := NewWatcher(NewLoader(s), &v, WatcherConfig{
+		OnChange: func(c []Change) {
+			changes = c
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	if v.Rate != 1 || v.Limits.Max != 10 {
+		t.Errorf("expected v to be loaded but was %v", v)
+	}
+	first := w.Current().(*testWatchedSettings)
+	if !reflect.DeepEqual(*first, v) {
+		t.Errorf("expected current to be %v but was %v", v, *first)
+	}
+
+	s.set("rate", "2", nil)
+	s.set("enabled", "true", nil)
+	err = w.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := []Change{
+		{Path: "Rate", Old: 1, New: 2},
+		{Path: "Enabled", Old: false, New: true},
+	}
+	if !reflect.DeepEqual(out, changes) {
+		t.Errorf("expected changes to be %v but was %v", out, changes)
+	}
+	current := w.Current().(*testWatchedSettings)
+	if current.Rate != 2 || !current.Enabled || current.Name != "worker" {
+		t.Errorf("expected current to be reloaded but was %v", *current)
+	}
+	if first.Rate != 1 {
+		t.Errorf("expected previous values not to be modified but was %v", *first)
+	}
+
+	// Failed reloads keep the current values.
+	s.set("rate", "3", errors.New("failed"))
+	err = w.Reload()
+	if err == nil || err.Error() != "config: error loading field Rate for tag test: failed" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	if w.Current() != current {
+		t.Errorf("expected current not to change")
+	}
+}
+
+func TestWatcherInterval(t *testing.T) {
+	s := &testMutableSource{values: map[string]string{"rate": "1"}}
+	changed := make(chan []Change, 1)
+	failed := make(chan error, 1)
+	var v testWatchedSettings
+	w, err := NewWatcher(NewLoader(s), &v, WatcherConfig{
+		Interval: time.Millisecond,
+		OnChange: func(c []Change) {
+			select {
+			case changed <- c:
+			default:
+			}
+		},
+		OnError: func(err error) {
+			select {
+			case failed <- err:
+			default:
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	s.set("rate", "5", nil)
+	select {
+	case c := <-changed:
+		out := []Change{{Path: "Rate", Old: 1, New: 5}}
+		if !reflect.DeepEqual(out, c) {
+			t.Errorf("expected changes to be %v but was %v", out, c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected values to be reloaded")
+	}
+
+	s.set("rate", "5", errors.New("failed"))
+	select {
+	case err := <-failed:
+		if err.Error() != "config: error loading field Rate for tag test: failed" {
+			t.Errorf("unexpected error: '%v'", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected OnError to be called")
+	}
+}
+
+func TestWatcherCallbacks(t *testing.T) {
+	s := &testMutableSource{values: map[string]string{"rate": "1"}}
+	var w *Watcher
+	reloaded := make(chan error, 1)
+	closed := make(chan struct{})
+	var v testWatchedSettings
+	w, err := NewWatcher(NewLoader(s), &v, WatcherConfig{
+		Interval: time.Millisecond,
+		OnChange: func(c []Change) {
+			if c[0].New == 2 {
+				// Reloading from OnChange doesn't deadlock.
+				s.set("rate", "3", nil)
+				reloaded <- w.Reload()
+				return
+			}
+			w.Close()
+			close(closed)
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s.set("rate", "2", nil)
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected values to be reloaded")
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the watcher to be closed")
+	}
+	// Closing again doesn't panic.
+	w.Close()
+	if rate := w.Current().(*testWatchedSettings).Rate; rate != 3 {
+		t.Fatalf("expected rate to be 3 but was %d", rate)
+	}
+}
+
+func TestNewWatcherErrors(t *testing.T) {
+	s := &testMutableSource{values: map[string]string{}}
+	_, err := NewWatcher(NewLoader(s), testWatchedSettings{}, WatcherConfig{})
+	if err == nil || err.Error() != "config: v is not a pointer" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+	_, err = NewWatcher(NewLoader(s), &testWatchedSettings{}, WatcherConfig{})
+	if err == nil || err.Error() != "config: missing value for field 'Rate'" {
+		t.Errorf("unexpected error: '%v'", err)
+	}
+}
+
+type testWatchableSource struct {
+	testMutableSource
+	events chan string
+}
+
+func (ts *testWatchableSource) Watch(stop <-chan struct{}, events chan<- string) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		case name := <-ts.events:
+			select {
+			case events <- name:
+			case <-stop:
+				return nil
+			}
+		}
+	}
+}
+
+func TestWatcherWatchableSource(t *testing.T) {
+	s := &testWatchableSource{
+		testMutableSource: testMutableSource{values: map[string]string{"rate": "1", "max": "5"}},
+		events:            make(chan string),
+	}
+	changed := make(chan []Change)
+	var v testWatchedSettings
+	w, err := NewWatcher(NewLoader(s), &v, WatcherConfig{
+		OnChange: func(c []Change) {
+			changed <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	// Only the fields loaded from the notified keys are reloaded.
+	s.set("rate", "2", nil)
+	s.set("max", "20", nil)
+	s.events <- "max"
+	select {
+	case c := <-changed:
+		out := []Change{{Path: "Limits.Max", Old: 5, New: 20}}
+		if !reflect.DeepEqual(out, c) {
+			t.Errorf("expected changes to be %v but was %v", out, c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected values to be reloaded")
+	}
+	current := w.Current().(*testWatchedSettings)
+	if current.Rate != 1 || current.Limits.Max != 20 {
+		t.Errorf("expected only max to be reloaded but was %v", *current)
+	}
+}
+
+func TestWatcherWatchableSourceDeletedKey(t *testing.T) {
+	s := &testWatchableSource{
+		testMutableSource: testMutableSource{values: map[string]string{"flag": "on"}},
+		events:            make(chan string),
+	}
+	changed := make(chan []Change)
+	var v struct {
+		Flag string `test:"flag,optional"`
+	}
+	w, err := NewWatcher(NewLoader(s), &v, WatcherConfig{
+		OnChange: func(c []Change) {
+			changed <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	// A key that is not set any more resets the field, like a full reload.
+	s.set("flag", "", nil)
+	s.events <- "flag"
+	select {
+	case c := <-changed:
+		out := []Change{{Path: "Flag", Old: "on", New: ""}}
+		if !reflect.DeepEqual(out, c) {
+			t.Errorf("expected changes to be %v but was %v", out, c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected values to be reloaded")
+	}
+}